@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Оценки ходов при разборе партии.
+const (
+	verdictMissedWin   = "missed win"   // До хода выигрыш был, после — нет.
+	verdictAllowedLoss = "allowed loss" // До хода проигрыша не было, после — есть.
+)
+
+// MoveAnalysis — разбор одного хода партии. Оценки даны с точки зрения сделавшего ход:
+// Before — оценка позиции при лучшем ходе, After — при сделанном.
+type MoveAnalysis struct {
+	Ply     int    `json:"ply"`
+	Player  string `json:"player"`
+	Move    string `json:"move"`
+	Before  int    `json:"before"`
+	After   int    `json:"after"`
+	Best    string `json:"best"`
+	Verdict string `json:"verdict,omitempty"`
+}
+
+// analyzeGame разбирает партию, начатую в позиции start и сыгранную ходами moves.
+// Каждая позиция оценивается перебором minimax на глубину explainDepth.
+// Отмена ctx прерывает разбор с ошибкой ctx.Err().
+func analyzeGame(ctx context.Context, start Rules, moves []Move) ([]MoveAnalysis, error) {
+	report := make([]MoveAnalysis, 0, len(moves))
+	r := start
+	for ply, m := range moves {
+		legal, vals, _, ok := moveValues(ctx, r, explainDepth(r))
+		if !ok {
+			return nil, ctx.Err()
+		}
+		played, best := -1, 0
+		for i, lm := range legal {
+			if lm == m {
+				played = i
+			}
+			if vals[i] > vals[best] {
+				best = i
+			}
+		}
+		if played < 0 {
+			return nil, fmt.Errorf("ход %d: недопустимый ход %s", ply+1, formatMove(r, m))
+		}
+
+		a := MoveAnalysis{
+			Ply:    ply + 1,
+			Player: symbolName(r.ToMove()),
+			Move:   formatMove(r, m),
+			Before: vals[best],
+			After:  vals[played],
+			Best:   formatMove(r, legal[best]),
+		}
+		switch {
+		case a.Before >= winScore && a.After < winScore:
+			a.Verdict = verdictMissedWin
+		case a.Before > -winScore && a.After <= -winScore:
+			a.Verdict = verdictAllowedLoss
+		}
+		report = append(report, a)
+		r = r.Apply(m)
+	}
+	return report, nil
+}
+
+// formatValue записывает оценку: доказанные выигрыш и проигрыш словами, остальное числом.
+func formatValue(v int) string {
+	switch {
+	case v >= winScore:
+		return "win"
+	case v <= -winScore:
+		return "loss"
+	}
+	return fmt.Sprintf("%+d", v)
+}
+
+// formatAnalysis записывает разбор партии таблицей: по строке на ход и итог ошибок сторон.
+// Для ошибочных ходов указывается лучший ход.
+func formatAnalysis(report []MoveAnalysis) string {
+	var b strings.Builder
+	mistakes := make(map[string]int)
+	for _, a := range report {
+		line := fmt.Sprintf("%2d. %s %-7s %5s -> %-5s", a.Ply, a.Player, a.Move, formatValue(a.Before), formatValue(a.After))
+		if a.Verdict != "" {
+			mistakes[a.Player]++
+			line += fmt.Sprintf(" %s, best %s", a.Verdict, a.Best)
+		}
+		b.WriteString(strings.TrimRight(line, " "))
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "mistakes: X %d, O %d\n", mistakes[symbolName(Cross)], mistakes[symbolName(Circle)])
+	return b.String()
+}
+
+// runAnalyze реализует подкоманду "analyze": читает ходы партии из in, по одному в строке,
+// начиная с позиции start, и выводит разбор текстом или в JSON.
+func runAnalyze(in io.Reader, out io.Writer, start Rules, asJSON bool) error {
+	var moves []Move
+	r := start
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		m, err := parseMove(r, line)
+		if err != nil {
+			return fmt.Errorf("ход %d: %w", len(moves)+1, err)
+		}
+		moves = append(moves, m)
+		r = r.Apply(m)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	report, err := analyzeGame(context.Background(), start, moves)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	_, err = io.WriteString(out, formatAnalysis(report))
+	return err
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeGame(t *testing.T) {
+	// Нолики отвечают на угол крестиков краем и проигрывают,
+	// а крестики, поставив угрозу, затем упускают выигрыш.
+	r := newBitRules(boardSize, winLength, false)
+	moves := []string{"0,0", "0,1", "1,1", "2,2", "2,1"}
+	var seq []Move
+	pos := Rules(r)
+	for _, s := range moves {
+		m, err := parseMove(pos, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seq = append(seq, m)
+		pos = pos.Apply(m)
+	}
+	report, err := analyzeGame(context.Background(), r, seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report) != len(moves) {
+		t.Fatalf("Разобрано %d ходов из %d", len(report), len(moves))
+	}
+	if a := report[0]; a.Verdict != "" || a.Before != 0 || a.After != 0 || a.Player != "X" {
+		t.Errorf("Первый ход оценён неверно: %+v", a)
+	}
+	if a := report[1]; a.Verdict != verdictAllowedLoss || a.Best != "1,1" || a.After > -winScore {
+		t.Errorf("Не найдена ошибка ноликов: %+v", a)
+	}
+	if a := report[2]; a.Verdict != "" || a.Before < winScore {
+		t.Errorf("Выигрывающий ход крестиков оценён неверно: %+v", a)
+	}
+	if a := report[4]; a.Verdict != verdictMissedWin || a.Best != "1,0" || a.After >= winScore {
+		t.Errorf("Не найден упущенный выигрыш: %+v", a)
+	}
+	for _, a := range report {
+		if a.After > a.Before {
+			t.Errorf("Сделанный ход лучше лучшего: %+v", a)
+		}
+	}
+}
+
+func TestAnalyzeGameIllegalMove(t *testing.T) {
+	r := newBitRules(boardSize, winLength, false)
+	m := Move{Row: 1, Col: 1, Symbol: Cross}
+	if _, err := analyzeGame(context.Background(), r, []Move{m, m}); err == nil {
+		t.Errorf("Ожидалась ошибка для хода в занятую клетку")
+	}
+}
+
+func TestRunAnalyze(t *testing.T) {
+	in := "0,0\n0,1\n\n1,1\n"
+	var out bytes.Buffer
+	if err := runAnalyze(strings.NewReader(in), &out, newBitRules(boardSize, winLength, false), false); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+	if !strings.Contains(text, "allowed loss, best 1,1") || !strings.Contains(text, "mistakes: X 0, O 1") {
+		t.Errorf("Неверный текстовый разбор:\n%s", text)
+	}
+
+	out.Reset()
+	if err := runAnalyze(strings.NewReader(in), &out, newBitRules(boardSize, winLength, false), true); err != nil {
+		t.Fatal(err)
+	}
+	var report []MoveAnalysis
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("Разбор не в JSON: %v\n%s", err, out.String())
+	}
+	if len(report) != 3 || report[1].Verdict != verdictAllowedLoss {
+		t.Errorf("Неверный разбор в JSON: %+v", report)
+	}
+
+	if err := runAnalyze(strings.NewReader("1,1\n1,1\n"), &out, newBitRules(boardSize, winLength, false), false); err == nil {
+		t.Errorf("Ожидалась ошибка для недопустимого хода")
+	}
+}
+
+func TestGameReport(t *testing.T) {
+	// Разбор в окне строится по ходам текущей партии.
+	resetGame()
+	defer resetGame()
+	for _, m := range []Move{{Row: 0, Col: 0}, {Row: 1, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}, {Row: 0, Col: 2}} {
+		m.Symbol = game.ToMove()
+		playMove(m)
+	}
+	if !gameOver || len(played) != 5 {
+		t.Fatalf("Партия не окончена или ходы не записаны: %d", len(played))
+	}
+	toggleReport()
+	if !showReport || analysing == nil {
+		t.Fatal("Разбор не запущен в фоне")
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for analysing != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("Разбор не закончился")
+		}
+		updateReport()
+		time.Sleep(time.Millisecond)
+	}
+	if len(report) != 5 {
+		t.Fatalf("Разбор не построен: %+v", report)
+	}
+	if report[1].Verdict != verdictAllowedLoss || report[3].Verdict != "" {
+		t.Errorf("Ошибка ноликов найдена неверно: %+v", report)
+	}
+	toggleReport()
+	if showReport {
+		t.Errorf("Разбор не закрылся")
+	}
+}
+
+func TestGameReportCancel(t *testing.T) {
+	// Новая партия прерывает разбор прежней.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := newClassicRules(15, 5, false)
+	if _, err := analyzeGame(ctx, r, []Move{{Row: 7, Col: 7, Symbol: Cross}}); err == nil {
+		t.Error("Отменённый разбор закончился без ошибки")
+	}
+
+	resetGame()
+	gameOver, history, played = true, []Rules{r}, []Move{{Row: 7, Col: 7, Symbol: Cross}}
+	toggleReport()
+	a := analysing
+	resetGame()
+	if analysing != nil || report != nil {
+		t.Error("Сброс не отменил разбор")
+	}
+	select {
+	case <-a.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Разбор не прервался")
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+)
+
+// searchResult — итог фонового поиска, передаваемый в игровой цикл.
+type searchResult struct {
+	Move   Move
+	Info   SearchInfo
+	OK     bool
+	Reason Reason // Объяснение хода, если это поиск объяснения.
+}
+
+// backgroundSearch — поиск, идущий в отдельной горутине. Позиция и движок передаются
+// горутине при запуске, поэтому она не обращается к глобальному состоянию игры.
+type backgroundSearch struct {
+	position Rules
+	cancel   context.CancelFunc
+	done     chan searchResult // Буферизован, чтобы горутина завершалась, даже если результат никто не ждёт.
+}
+
+// startSearch запускает поиск хода движком e в позиции r.
+func startSearch(ctx context.Context, e Engine, r Rules) *backgroundSearch {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &backgroundSearch{position: r, cancel: cancel, done: make(chan searchResult, 1)}
+	go func() {
+		m, info, ok := e.BestMove(ctx, r)
+		s.done <- searchResult{Move: m, Info: info, OK: ok && ctx.Err() == nil}
+	}()
+	return s
+}
+
+// startExplain объясняет ход m в позиции r. Объяснение ищется отдельно от хода,
+// когда он уже сделан, поэтому не тратит время компьютера на часах.
+func startExplain(ctx context.Context, r Rules, m Move) *backgroundSearch {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &backgroundSearch{position: r, cancel: cancel, done: make(chan searchResult, 1)}
+	go func() {
+		reason, ok := explain(ctx, r, m, explainDepth(r))
+		s.done <- searchResult{Move: m, OK: ok && ctx.Err() == nil, Reason: reason}
+	}()
+	return s
+}
+
+// analysisResult — итог фонового разбора партии.
+type analysisResult struct {
+	Report []MoveAnalysis
+	Err    error
+}
+
+// backgroundAnalysis — разбор партии, идущий в отдельной горутине, как backgroundSearch.
+type backgroundAnalysis struct {
+	cancel context.CancelFunc
+	done   chan analysisResult // Буферизован, как у backgroundSearch.
+}
+
+// startAnalysis разбирает партию, начатую в позиции start и сыгранную ходами moves.
+func startAnalysis(ctx context.Context, start Rules, moves []Move) *backgroundAnalysis {
+	ctx, cancel := context.WithCancel(ctx)
+	a := &backgroundAnalysis{cancel: cancel, done: make(chan analysisResult, 1)}
+	go func() {
+		report, err := analyzeGame(ctx, start, moves)
+		a.done <- analysisResult{Report: report, Err: err}
+	}()
+	return a
+}
+
+// poll возвращает разбор, если он закончен, не блокируя игровой цикл.
+func (a *backgroundAnalysis) poll() (analysisResult, bool) {
+	select {
+	case res := <-a.done:
+		a.cancel()
+		return res, true
+	default:
+		return analysisResult{}, false
+	}
+}
+
+// poll возвращает результат, если поиск завершён, не блокируя игровой цикл.
+func (s *backgroundSearch) poll() (searchResult, bool) {
+	select {
+	case res := <-s.done:
+		s.cancel()
+		return res, true
+	default:
+		return searchResult{}, false
+	}
+}
+
+// pending — текущий фоновый поиск игры; searched — позиция, для которой поиск уже завершён.
+// explaining — поиск объяснения последнего найденного хода с подписью explainer; оно
+// показывается, только если позиция игры всё ещё explainedAt.
+// Все переменные, как и остальное состояние игры, меняются только в игровом цикле.
+var (
+	pending     *backgroundSearch
+	searched    Rules
+	explaining  *backgroundSearch
+	explainer   string
+	explainedAt Rules
+)
+
+// cancelSearch прерывает текущий поиск и объяснение; их результаты будут отброшены.
+func cancelSearch() {
+	if pending != nil {
+		pending.cancel()
+		pending = nil
+	}
+	if explaining != nil {
+		explaining.cancel()
+		explaining = nil
+	}
+}
+
+// wantsSearch сообщает, нужен ли в текущей позиции поиск: ход компьютера
+// или подсказка ноликам в игре двух людей.
+func wantsSearch() bool {
+	if gameOver || game == searched {
+		return false
+	}
+	return game.ToMove() == aiSide || aiSide == Empty && game.ToMove() == Circle
+}
+
+// updateSearch запускает поиск, когда он нужен, и применяет готовый результат:
+// ход компьютера делается, а для человека запоминается подсказка. В обоих случаях
+// затем ищется объяснение хода.
+func updateSearch() {
+	updateExplain()
+	if pending == nil {
+		if wantsSearch() {
+			e := ai
+			if game.ToMove() == aiSide {
+				e = timedEngine(ai, clock, aiSide)
+			}
+			pending = startSearch(context.Background(), e, game)
+		}
+		return
+	}
+
+	res, ok := pending.poll()
+	if !ok {
+		return
+	}
+	pending = nil
+	searched = game
+	if !res.OK {
+		return
+	}
+	lastSearch = res.Info
+	r, who := game, "hint"
+	if game.ToMove() == aiSide {
+		playMove(res.Move)
+		who = "computer"
+	} else {
+		hint = &res.Move
+	}
+	if explaining != nil {
+		explaining.cancel()
+	}
+	explaining, explainer, explainedAt = startExplain(context.Background(), r, res.Move), who, game
+}
+
+// updateExplain показывает готовое объяснение, если с тех пор не было других ходов.
+func updateExplain() {
+	if explaining == nil {
+		return
+	}
+	res, ok := explaining.poll()
+	if !ok {
+		return
+	}
+	explaining = nil
+	if res.OK && game == explainedAt {
+		explanation = explainer + ": " + string(res.Reason)
+	}
+}
+
+// thinking сообщает, что компьютер сейчас ищет свой ход.
+func thinking() bool {
+	return pending != nil && game.ToMove() == aiSide
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// waitResult ждёт результата фонового поиска не дольше timeout.
+func waitResult(t *testing.T, s *backgroundSearch, timeout time.Duration) searchResult {
+	t.Helper()
+	select {
+	case res := <-s.done:
+		return res
+	case <-time.After(timeout):
+		t.Fatalf("Поиск не завершился за %v", timeout)
+	}
+	return searchResult{}
+}
+
+func TestBackgroundSearch(t *testing.T) {
+	r := Rules(newClassicRules(boardSize, winLength, false))
+	for _, m := range []Move{{Row: 0, Col: 0}, {Row: 1, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}} {
+		r = r.Apply(m)
+	}
+	res := waitResult(t, startSearch(context.Background(), minimaxEngine{Depth: 5}, r), 5*time.Second)
+	if !res.OK || res.Move.Row != 0 || res.Move.Col != 2 {
+		t.Errorf("Ожидался ход (0, 2), но получено %+v", res)
+	}
+}
+
+func TestCancelDuringSearch(t *testing.T) {
+	// Ни один из движков не успел бы закончить поиск на пустом поле 5×5 за время теста.
+	engines := []Engine{
+		minimaxEngine{Depth: 25},
+		minimaxEngine{Depth: 25, Budget: time.Hour},
+		&mctsEngine{Iterations: 1 << 30},
+	}
+	for _, e := range engines {
+		s := startSearch(context.Background(), e, newClassicRules(5, 4, false))
+		time.Sleep(20 * time.Millisecond)
+		s.cancel()
+		if res := waitResult(t, s, 5*time.Second); res.OK {
+			t.Errorf("%s: отменённый поиск вернул ход %v", e.Name(), res.Move)
+		}
+	}
+
+	// Отмена родительского контекста тоже прерывает поиск.
+	ctx, cancel := context.WithCancel(context.Background())
+	s := startSearch(ctx, minimaxEngine{Depth: 25}, newClassicRules(5, 4, false))
+	cancel()
+	if res := waitResult(t, s, 5*time.Second); res.OK {
+		t.Errorf("Поиск после отмены контекста вернул ход %v", res.Move)
+	}
+}
+
+func TestBackgroundExplain(t *testing.T) {
+	r := classicFromText(3, Circle, "XX.", ".O.", "...")
+	res := waitResult(t, startExplain(context.Background(), r, Move{Row: 0, Col: 2, Symbol: Circle}), 5*time.Second)
+	if !res.OK || res.Reason != reasonBlock {
+		t.Errorf("Ожидалось объяснение %q, но получено %+v", reasonBlock, res)
+	}
+
+	// Объяснение хода на большом поле прерывается, как поиск.
+	ctx, cancel := context.WithCancel(context.Background())
+	s := startExplain(ctx, newClassicRules(15, 5, false), Move{Row: 7, Col: 7, Symbol: Cross})
+	cancel()
+	if res := waitResult(t, s, 5*time.Second); res.OK {
+		t.Errorf("Отменённое объяснение вернуло %q", res.Reason)
+	}
+}
+
+// waitTurn прокручивает игровой цикл, пока не настанет очередь игрока p.
+func waitTurn(t *testing.T, p Player) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for game.ToMove() != p {
+		if time.Now().After(deadline) {
+			t.Fatal("Компьютер не сделал ход")
+		}
+		updateSearch()
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGameLoopSearch(t *testing.T) {
+	gameMode = modeGravity
+	defer func() {
+		gameMode = modeClassic
+		resetGame()
+	}()
+	resetGame()
+
+	// Ход компьютера приходит через несколько кадров, не блокируя цикл.
+	playMove(Move{Col: 3, Row: 5, Symbol: Cross})
+	updateSearch()
+	if !thinking() {
+		t.Errorf("Компьютер должен думать над ходом")
+	}
+	waitTurn(t, Cross)
+	if moveCount != 2 || lastSearch.Nodes == 0 {
+		t.Errorf("Ожидалось 2 хода и сведения о поиске, но получено %d, %+v", moveCount, lastSearch)
+	}
+
+	// Отмена хода во время поиска возвращает позицию до хода человека.
+	before := game
+	playMove(movesAt(game, Cell3{0, 0, 0})[0])
+	updateSearch()
+	undoMove()
+	if pending != nil || game != before || moveCount != 2 {
+		t.Errorf("Отмена хода не вернула прежнюю позицию")
+	}
+
+	// Отмена после ответа компьютера снимает и его ход.
+	playMove(movesAt(game, Cell3{0, 0, 0})[0])
+	waitTurn(t, Cross)
+	undoMove()
+	if game != before || moveCount != 2 {
+		t.Errorf("Отмена хода не сняла ответ компьютера")
+	}
+
+	// Сброс во время поиска отменяет его.
+	playMove(movesAt(game, Cell3{0, 0, 0})[0])
+	updateSearch()
+	s := pending
+	resetGame()
+	if pending != nil || moveCount != 0 {
+		t.Errorf("Сброс не отменил поиск")
+	}
+	waitResult(t, s, 5*time.Second)
+}
+
+func TestHintSearch(t *testing.T) {
+	// В игре двух людей ноликам подсказывается ход, но сам он не делается.
+	resetGame()
+	defer resetGame()
+	playMove(Move{Row: 0, Col: 0, Symbol: Cross})
+	deadline := time.Now().Add(10 * time.Second)
+	for hint == nil || explanation == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("Подсказка не появилась")
+		}
+		updateSearch()
+		time.Sleep(time.Millisecond)
+	}
+	if game.ToMove() != Circle || hint.Row != 1 || hint.Col != 1 {
+		t.Errorf("Ожидалась подсказка (1, 1) без хода, но получено %v", *hint)
+	}
+	if explanation != "hint: "+string(reasonCenter) {
+		t.Errorf("Неверное объяснение подсказки: %q", explanation)
+	}
+}
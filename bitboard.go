@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+)
+
+// maxBitboardCells — наибольшее число клеток поля, помещающееся в одну битовую маску.
+const maxBitboardCells = 64
+
+// bitGeometry — маски, зависящие только от размеров поля: все отрезки длины k,
+// углы, центр и всё поле. Общие для всех позиций партии.
+type bitGeometry struct {
+	lines   []uint64
+	corners uint64
+	center  uint64
+	full    uint64
+}
+
+// newBitGeometry вычисляет маски поля size×size с выигрышной длиной k.
+func newBitGeometry(size, k int) *bitGeometry {
+	g := &bitGeometry{}
+	for _, line := range gridLines(size, size, k) {
+		var m uint64
+		for _, c := range line {
+			m |= 1 << uint(c.Row*size+c.Col)
+		}
+		g.lines = append(g.lines, m)
+	}
+	last, lo, hi := size-1, (size-1)/2, size/2
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			bit := uint64(1) << uint(row*size+col)
+			g.full |= bit
+			if (row == 0 || row == last) && (col == 0 || col == last) {
+				g.corners |= bit
+			}
+			if row >= lo && row <= hi && col >= lo && col <= hi {
+				g.center |= bit
+			}
+		}
+	}
+	return g
+}
+
+// BitRules — те же правила, что ClassicRules, но позиция хранится битовыми масками,
+// по одной на игрока. Проверка линий и оценка сводятся к операциям над масками,
+// а копирование позиции — к копированию нескольких слов, поэтому перебор идёт быстрее.
+// Подходит для полей не больше 8×8.
+type BitRules struct {
+	Size, K int
+	Bits    [2]uint64 // Фишки ноликов и крестиков; индекс — bitIndex игрока.
+	Turn    Player
+	Misere  bool
+	Weights HeuristicWeights
+	geo     *bitGeometry
+}
+
+// bitIndex возвращает индекс маски игрока p в BitRules.Bits.
+func bitIndex(p Player) int {
+	return int(p) - 1
+}
+
+// newBitRules создаёт пустое поле, на котором первыми ходят крестики.
+func newBitRules(size, k int, misere bool) *BitRules {
+	if size*size > maxBitboardCells {
+		panic(fmt.Sprintf("поле %d×%d не помещается в битовую маску", size, size))
+	}
+	return &BitRules{Size: size, K: k, Turn: Cross, Misere: misere, Weights: heuristicWeights, geo: newBitGeometry(size, k)}
+}
+
+// bitRulesFromClassic переводит позицию ClassicRules в битовое представление.
+func bitRulesFromClassic(r *ClassicRules) *BitRules {
+	b := newBitRules(r.Size, r.K, r.Misere)
+	b.Turn = r.Turn
+	b.Weights = r.Weights
+	for i, p := range r.Cells {
+		if p != Empty {
+			b.Bits[bitIndex(p)] |= 1 << uint(i)
+		}
+	}
+	return b
+}
+
+// bitRulesFromArray переводит классическое поле 3×3 в битовое представление с ходом игрока turn.
+func bitRulesFromArray(a [boardSize][boardSize]Player, turn Player) *BitRules {
+	return bitRulesFromClassic(classicFromBoard(a, turn))
+}
+
+// Classic возвращает ту же позицию в виде ClassicRules.
+func (b *BitRules) Classic() *ClassicRules {
+	r := newClassicRules(b.Size, b.K, b.Misere)
+	r.Turn = b.Turn
+	r.Weights = b.Weights
+	for i := range r.Cells {
+		r.Cells[i] = b.at(i)
+	}
+	return r
+}
+
+// Array возвращает позицию поля 3×3 в виде массива, с которым работает классический интерфейс.
+func (b *BitRules) Array() [boardSize][boardSize]Player {
+	var a [boardSize][boardSize]Player
+	for i := 0; i < boardSize; i++ {
+		for j := 0; j < boardSize; j++ {
+			a[i][j] = b.at(i*b.Size + j)
+		}
+	}
+	return a
+}
+
+// at возвращает фишку в клетке с номером i.
+func (b *BitRules) at(i int) Player {
+	bit := uint64(1) << uint(i)
+	switch {
+	case b.Bits[bitIndex(Circle)]&bit != 0:
+		return Circle
+	case b.Bits[bitIndex(Cross)]&bit != 0:
+		return Cross
+	}
+	return Empty
+}
+
+func (b *BitRules) Name() string {
+	return b.Classic().Name()
+}
+
+func (b *BitRules) ToMove() Player {
+	return b.Turn
+}
+
+func (b *BitRules) At(c Cell3) Player {
+	return b.at(c.Row*b.Size + c.Col)
+}
+
+// line возвращает владельца собранной линии и её маску.
+func (b *BitRules) line() (Player, uint64) {
+	for _, m := range b.geo.lines {
+		if b.Bits[bitIndex(Circle)]&m == m {
+			return Circle, m
+		}
+		if b.Bits[bitIndex(Cross)]&m == m {
+			return Cross, m
+		}
+	}
+	return Empty, 0
+}
+
+func (b *BitRules) Terminal() bool {
+	if p, _ := b.line(); p != Empty {
+		return true
+	}
+	return b.Bits[0]|b.Bits[1] == b.geo.full
+}
+
+func (b *BitRules) Winner() Player {
+	p, _ := b.line()
+	if p != Empty && b.Misere {
+		return other(p)
+	}
+	return p
+}
+
+func (b *BitRules) LegalMoves() []Move {
+	if b.Terminal() {
+		return nil
+	}
+	empty := b.geo.full &^ (b.Bits[0] | b.Bits[1])
+	moves := make([]Move, 0, bits.OnesCount64(empty))
+	for empty != 0 {
+		i := bits.TrailingZeros64(empty)
+		empty &= empty - 1
+		moves = append(moves, Move{Row: i / b.Size, Col: i % b.Size, Symbol: b.Turn})
+	}
+	return moves
+}
+
+func (b *BitRules) Apply(m Move) Rules {
+	next := *b
+	next.Bits[bitIndex(b.Turn)] |= 1 << uint(m.Row*b.Size+m.Col)
+	next.Turn = other(b.Turn)
+	return &next
+}
+
+// Evaluate оценивает позицию так же, как ClassicRules.Evaluate.
+func (b *BitRules) Evaluate() int {
+	score := b.sideScore(b.Turn) - b.sideScore(other(b.Turn))
+	if b.Misere {
+		return -score
+	}
+	return score
+}
+
+// sideScore считает показатели игрока p по маскам; см. ClassicRules.sideScore.
+func (b *BitRules) sideScore(p Player) int {
+	w := b.Weights
+	own, opp := b.Bits[bitIndex(p)], b.Bits[bitIndex(other(p))]
+	empty := b.geo.full &^ (own | opp)
+
+	score := 0
+	var first uint64
+	fork := false
+	for _, m := range b.geo.lines {
+		if opp&m != 0 {
+			continue
+		}
+		n := bits.OnesCount64(own & m)
+		switch {
+		case n == 1:
+			score += w.Open
+		case n == 2:
+			score += w.Two
+		case n >= 3:
+			score += w.Three
+		}
+		if n == b.K-1 {
+			cell := empty & m
+			if first == 0 {
+				first = cell
+			} else if cell != first {
+				fork = true
+			}
+		}
+	}
+	if fork {
+		score += w.Fork
+	}
+	score += w.Corner*bits.OnesCount64(own&b.geo.corners) + w.Center*bits.OnesCount64(own&b.geo.center)
+	return score
+}
+
+func (b *BitRules) Hints() RenderHints {
+	return b.Classic().Hints()
+}
+
+// ForcedWin ищет выигрыш угрозами в той же позиции в виде ClassicRules:
+// поиск угроз вызывается один раз на ход, и его скорость не так важна.
+func (b *BitRules) ForcedWin(ctx context.Context) ([]Move, int, bool) {
+	return b.Classic().ForcedWin(ctx)
+}
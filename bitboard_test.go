@@ -0,0 +1,93 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestBitboardMatchesClassic(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, size := range [][2]int{{3, 3}, {4, 3}, {5, 4}, {8, 5}} {
+		for _, misere := range []bool{false, true} {
+			for game := 0; game < 20; game++ {
+				c := newClassicRules(size[0], size[1], misere)
+				b := newBitRules(size[0], size[1], misere)
+				for {
+					if !reflect.DeepEqual(b.Classic().Cells, c.Cells) || !reflect.DeepEqual(bitRulesFromClassic(c).Bits, b.Bits) {
+						t.Fatalf("%s: позиции разошлись", c.Name())
+					}
+					if b.Terminal() != c.Terminal() || b.Winner() != c.Winner() {
+						t.Fatalf("%s: разный итог партии", c.Name())
+					}
+					if !reflect.DeepEqual(b.Hints(), c.Hints()) {
+						t.Fatalf("%s: разные подсказки отрисовки", c.Name())
+					}
+					moves := c.LegalMoves()
+					if len(moves) == 0 {
+						if len(b.LegalMoves()) != 0 {
+							t.Fatalf("%s: у битового поля остались ходы", c.Name())
+						}
+						break
+					}
+					if !reflect.DeepEqual(b.LegalMoves(), moves) {
+						t.Fatalf("%s: разные списки ходов", c.Name())
+					}
+					if b.Evaluate() != c.Evaluate() {
+						t.Fatalf("%s: разные оценки %d и %d", c.Name(), b.Evaluate(), c.Evaluate())
+					}
+					m := moves[rnd.Intn(len(moves))]
+					c = c.Apply(m).(*ClassicRules)
+					b = b.Apply(m).(*BitRules)
+				}
+			}
+		}
+	}
+}
+
+func TestBitboardArray(t *testing.T) {
+	a := [boardSize][boardSize]Player{
+		{Cross, Empty, Circle},
+		{Empty, Cross, Empty},
+		{Circle, Empty, Empty},
+	}
+	b := bitRulesFromArray(a, Cross)
+	if b.Array() != a || b.ToMove() != Cross {
+		t.Errorf("Поле после преобразования изменилось: %v", b.Array())
+	}
+	if b.Bits[bitIndex(Cross)] != 1<<0|1<<4 || b.Bits[bitIndex(Circle)] != 1<<2|1<<6 {
+		t.Errorf("Неверные маски %b %b", b.Bits[bitIndex(Cross)], b.Bits[bitIndex(Circle)])
+	}
+	// Apply не меняет исходную позицию.
+	b.Apply(Move{Row: 2, Col: 2})
+	if b.Array() != a {
+		t.Errorf("Apply изменил исходную позицию")
+	}
+}
+
+// benchmarkNodes перебирает позицию на глубину depth и сообщает скорость в позициях в секунду.
+func benchmarkNodes(b *testing.B, r Rules, depth int) {
+	nodes := 0
+	for i := 0; i < b.N; i++ {
+		var s searcher
+		s.root(r, r.LegalMoves(), depth)
+		nodes += s.nodes
+	}
+	b.ReportMetric(float64(nodes)/b.Elapsed().Seconds(), "nodes/s")
+}
+
+func BenchmarkSearchClassic3x3(b *testing.B) {
+	benchmarkNodes(b, newClassicRules(3, 3, false), 9)
+}
+
+func BenchmarkSearchBitboard3x3(b *testing.B) {
+	benchmarkNodes(b, newBitRules(3, 3, false), 9)
+}
+
+func BenchmarkSearchClassic5x5(b *testing.B) {
+	benchmarkNodes(b, newClassicRules(5, 4, false), 4)
+}
+
+func BenchmarkSearchBitboard5x5(b *testing.B) {
+	benchmarkNodes(b, newBitRules(5, 4, false), 4)
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BookMove — ход дебютной книги и его вес: чем больше вес, тем чаще ход выбирается.
+type BookMove struct {
+	Move   string  `json:"move"`
+	Weight float64 `json:"weight"`
+}
+
+// BookEntry — позиция дебютной книги и предпочтительные ходы в ней. Позиция записывается
+// названием варианта, строками поля через "/" и ходящим, ходы — как в formatMove.
+type BookEntry struct {
+	Variant string     `json:"variant"`
+	Board   string     `json:"board"`
+	ToMove  string     `json:"to_move"`
+	Moves   []BookMove `json:"moves"`
+}
+
+func (e BookEntry) key() string {
+	return e.Variant + " " + e.Board + " " + e.ToMove
+}
+
+// bookEntryFor возвращает запись книги для позиции r без ходов.
+func bookEntryFor(r Rules) BookEntry {
+	board := strings.TrimSuffix(renderText(r), "\n")
+	// Слои многослойных полей разделяются пробелами, которые в тексте книги разделяют поля записи.
+	board = strings.ReplaceAll(strings.ReplaceAll(board, "  ", "|"), "\n", "/")
+	return BookEntry{Variant: r.Name(), Board: board, ToMove: symbolName(r.ToMove())}
+}
+
+// bookable сообщает, что позицию варианта r целиком задают поле и ходящий, поэтому
+// для неё можно записать и найти ход книги. В ultimate по полю не видно, на каком
+// малом поле следующий ход, а в morris исход зависит от повторений позиции.
+func bookable(r Rules) bool {
+	switch r.(type) {
+	case *UltimateBoard, *MorrisGame:
+		return false
+	}
+	return true
+}
+
+// Book — дебютная книга: позиции и ходы, которые компьютер играет без перебора.
+type Book struct {
+	Entries []BookEntry
+	index   map[string]int
+}
+
+// newBook собирает книгу из записей; повторная запись позиции заменяет прежнюю.
+func newBook(entries []BookEntry) *Book {
+	b := &Book{index: make(map[string]int)}
+	for _, e := range entries {
+		if i, ok := b.index[e.key()]; ok {
+			b.Entries[i] = e
+			continue
+		}
+		b.index[e.key()] = len(b.Entries)
+		b.Entries = append(b.Entries, e)
+	}
+	return b
+}
+
+// lookup возвращает ходы книги для позиции r. Для вариантов, которые не bookable,
+// ходов нет: одной записи там соответствуют разные позиции.
+func (b *Book) lookup(r Rules) []BookMove {
+	if !bookable(r) {
+		return nil
+	}
+	i, ok := b.index[bookEntryFor(r).key()]
+	if !ok {
+		return nil
+	}
+	return b.Entries[i].Moves
+}
+
+// choose выбирает ход книги в позиции r с вероятностью, пропорциональной весу.
+// Недопустимые ходы и ходы с нулевым весом пропускаются.
+func (b *Book) choose(r Rules, rnd *rand.Rand) (Move, bool) {
+	var moves []Move
+	var weights []float64
+	total := 0.0
+	for _, bm := range b.lookup(r) {
+		m, err := parseMove(r, bm.Move)
+		if err != nil || bm.Weight <= 0 {
+			continue
+		}
+		moves = append(moves, m)
+		weights = append(weights, bm.Weight)
+		total += bm.Weight
+	}
+	if len(moves) == 0 {
+		return Move{}, false
+	}
+	x := rnd.Float64() * total
+	for i, w := range weights {
+		x -= w
+		if x < 0 {
+			return moves[i], true
+		}
+	}
+	return moves[len(moves)-1], true
+}
+
+// parseBookText читает книгу в текстовом виде: по позиции в строке,
+// "вариант поле ходящий ход[:вес] ...". Вес по умолчанию равен 1; строки,
+// начинающиеся с "#", и пустые строки пропускаются.
+func parseBookText(in io.Reader) ([]BookEntry, error) {
+	var entries []BookEntry
+	scanner := bufio.NewScanner(in)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("строка %d: ожидались вариант, поле, ходящий и ходы", line)
+		}
+		e := BookEntry{Variant: fields[0], Board: fields[1], ToMove: fields[2]}
+		for _, f := range fields[3:] {
+			move, weight, hasWeight := strings.Cut(f, ":")
+			bm := BookMove{Move: move, Weight: 1}
+			if hasWeight {
+				w, err := strconv.ParseFloat(weight, 64)
+				if err != nil || w < 0 {
+					return nil, fmt.Errorf("строка %d: неверный вес %q", line, weight)
+				}
+				bm.Weight = w
+			}
+			e.Moves = append(e.Moves, bm)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// writeBookText записывает книгу в текстовом виде, который читает parseBookText.
+func writeBookText(w io.Writer, entries []BookEntry) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range entries {
+		fmt.Fprintf(bw, "%s %s %s", e.Variant, e.Board, e.ToMove)
+		for _, m := range e.Moves {
+			fmt.Fprintf(bw, " %s:%s", m.Move, strconv.FormatFloat(m.Weight, 'g', -1, 64))
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// loadBook читает книгу из файла: в JSON, если имя оканчивается на ".json", иначе из текста.
+func loadBook(path string) (*Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []BookEntry
+	if strings.HasSuffix(path, ".json") {
+		err = json.NewDecoder(f).Decode(&entries)
+	} else {
+		entries, err = parseBookText(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("чтение книги %s: %w", path, err)
+	}
+	return newBook(entries), nil
+}
+
+// generateBook строит книгу перебором: все позиции не глубже plies полуходов от начальной
+// оцениваются движком e, и в книгу записываются все ходы с лучшей оценкой с весом 1.
+// Позиции перебираются по слоям, поэтому запись воспроизводима.
+func generateBook(start Rules, plies int, e minimaxEngine) ([]BookEntry, error) {
+	if !bookable(start) {
+		return nil, fmt.Errorf("в варианте %s позицию не задают поле и ходящий, книга не строится", start.Name())
+	}
+	var entries []BookEntry
+	seen := map[string]bool{bookEntryFor(start).key(): true}
+	layer := []Rules{start}
+	for ply := 0; ply < plies && len(layer) > 0; ply++ {
+		var next []Rules
+		for _, r := range layer {
+			if r.Terminal() {
+				continue
+			}
+			entry := bookEntryFor(r)
+			for _, m := range optimalMoves(r, e) {
+				entry.Moves = append(entry.Moves, BookMove{Move: formatMove(r, m), Weight: 1})
+			}
+			entries = append(entries, entry)
+
+			for _, m := range r.LegalMoves() {
+				child := r.Apply(m)
+				if key := bookEntryFor(child).key(); !seen[key] {
+					seen[key] = true
+					next = append(next, child)
+				}
+			}
+		}
+		layer = next
+	}
+	return entries, nil
+}
+
+// optimalMoves возвращает все ходы позиции r с лучшей оценкой при переборе движком e:
+// итеративным углублением, если у движка есть время на ход, иначе на глубину e.Depth.
+func optimalMoves(r Rules, e minimaxEngine) []Move {
+	moves := r.LegalMoves()
+	var best Move
+	var val, depth int
+	if e.Budget > 0 {
+		var info SearchInfo
+		best, val, info, _ = iterativeDeepening(context.Background(), r, e.Depth, e.Workers, e.Budget)
+		depth = info.Depth
+	} else {
+		s := searcher{}
+		best, val = s.rootParallel(r, moves, e.Depth, e.Workers)
+		depth = e.Depth
+	}
+	equal, ok := equalMoves(context.Background(), time.Time{}, r, moves, depth, val)
+	if !ok || len(equal) == 0 {
+		return []Move{best}
+	}
+	return equal
+}
+
+// bookEngine сначала ищет ход в дебютной книге и переходит к перебору движком Engine,
+// если позиции в книге нет.
+type bookEngine struct {
+	Engine
+	Book *Book
+	Rand *rand.Rand
+}
+
+// withBook добавляет к движку e дебютную книгу b; без книги движок не меняется.
+// Среди ходов книги движок выбирает генератором из newEngineRand.
+func withBook(e Engine, b *Book) Engine {
+	if b == nil {
+		return e
+	}
+	return &bookEngine{Engine: e, Book: b, Rand: newEngineRand()}
+}
+
+// withBudget ограничивает временем d перебор, к которому движок переходит после книги;
+// книга и её генератор остаются прежними.
+func (e *bookEngine) withBudget(d time.Duration) Engine {
+	b, ok := e.Engine.(budgeted)
+	if !ok {
+		return e
+	}
+	return &bookEngine{Engine: b.withBudget(d), Book: e.Book, Rand: e.Rand}
+}
+
+func (e *bookEngine) BestMove(ctx context.Context, r Rules) (Move, SearchInfo, bool) {
+	start := time.Now()
+	if m, ok := e.Book.choose(r, e.Rand); ok {
+		return m, SearchInfo{Elapsed: time.Since(start)}, true
+	}
+	return e.Engine.BestMove(ctx, r)
+}
+
+// openingBook — дебютная книга компьютера, загружаемая флагом -book; nil — без книги.
+var openingBook *Book
+
+// runBook реализует подкоманду "book": строит книгу на plies полуходов движком e
+// и записывает её в w в текстовом виде.
+func runBook(w io.Writer, start Rules, plies int, e Engine) error {
+	m, ok := e.(minimaxEngine)
+	if !ok {
+		return fmt.Errorf("книгу строит только движок minimax, а не %s", e.Name())
+	}
+	entries, err := generateBook(start, plies, m)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "# %s, %d plies, %s\n", start.Name(), plies, m.Name())
+	return writeBookText(w, entries)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBookText(t *testing.T) {
+	text := `# дебюты
+classic .../.../... X 1,1:3 0,0
+
+classic X../.../... O 1,1:1 0,1:0
+`
+	entries, err := parseBookText(strings.NewReader(text))
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Прочитано %d записей (%v)", len(entries), err)
+	}
+	if e := entries[0]; e.Variant != "classic" || e.Board != ".../.../..." || e.ToMove != "X" ||
+		len(e.Moves) != 2 || e.Moves[0] != (BookMove{"1,1", 3}) || e.Moves[1] != (BookMove{"0,0", 1}) {
+		t.Errorf("Неверная запись: %+v", e)
+	}
+
+	var out bytes.Buffer
+	if err := writeBookText(&out, entries); err != nil {
+		t.Fatal(err)
+	}
+	again, err := parseBookText(&out)
+	if err != nil || len(again) != 2 || again[1].Moves[1] != (BookMove{"0,1", 0}) {
+		t.Errorf("Книга не читается после записи: %+v (%v)", again, err)
+	}
+
+	for _, bad := range []string{"classic .../.../... X", "classic .../.../... X 1,1:x", "classic .../.../... X 1,1:-1"} {
+		if _, err := parseBookText(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q: ожидалась ошибка", bad)
+		}
+	}
+}
+
+func TestBookChoose(t *testing.T) {
+	b := newBook([]BookEntry{
+		{Variant: "classic", Board: ".../.../...", ToMove: "X", Moves: []BookMove{{"1,1", 3}, {"0,0", 1}, {"9,9", 5}, {"2,2", 0}}},
+		{Variant: "classic", Board: "X../.../...", ToMove: "O", Moves: []BookMove{{"0,0", 1}}},
+	})
+	r := newBitRules(boardSize, winLength, false)
+	rnd := rand.New(rand.NewSource(1))
+	counts := make(map[Move]int)
+	for i := 0; i < 1000; i++ {
+		m, ok := b.choose(r, rnd)
+		if !ok {
+			t.Fatal("Ход книги не найден")
+		}
+		counts[m]++
+	}
+	// Недопустимый ход и ход с нулевым весом не выбираются, центр — втрое чаще угла.
+	center, corner := counts[Move{Row: 1, Col: 1, Symbol: Cross}], counts[Move{Row: 0, Col: 0, Symbol: Cross}]
+	if center+corner != 1000 || center < 700 || center > 800 {
+		t.Errorf("Неверное распределение ходов книги: %v", counts)
+	}
+
+	// В книге только занятая клетка — позиция считается отсутствующей.
+	if _, ok := b.choose(r.Apply(Move{Row: 0, Col: 0}), rnd); ok {
+		t.Errorf("Выбран недопустимый ход книги")
+	}
+	// Позиция другого варианта не совпадает с классической.
+	if _, ok := b.choose(newBitRules(boardSize, winLength, true), rnd); ok {
+		t.Errorf("Книга классики применена к мизеру")
+	}
+}
+
+func TestLoadBook(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "book.txt")
+	os.WriteFile(text, []byte("classic .../.../... X 1,1\n"), 0o644)
+	js := filepath.Join(dir, "book.json")
+	os.WriteFile(js, []byte(`[{"variant":"classic","board":".../.../...","to_move":"X","moves":[{"move":"1,1","weight":1}]}]`), 0o644)
+	r := newBitRules(boardSize, winLength, false)
+	for _, path := range []string{text, js} {
+		b, err := loadBook(path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if m, ok := b.choose(r, rand.New(rand.NewSource(1))); !ok || m.Row != 1 || m.Col != 1 {
+			t.Errorf("%s: ожидался ход 1,1, получено %v", path, m)
+		}
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte(`{`), 0o644)
+	if _, err := loadBook(bad); err == nil {
+		t.Errorf("Ожидалась ошибка для неверного JSON")
+	}
+	if _, err := loadBook(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Errorf("Ожидалась ошибка для отсутствующего файла")
+	}
+}
+
+func TestBookEngine(t *testing.T) {
+	// Позиция из книги не перебирается, остальные — перебираются.
+	b := newBook([]BookEntry{{Variant: "classic", Board: ".../.../...", ToMove: "X", Moves: []BookMove{{"2,1", 1}}}})
+	e := withBook(minimaxEngine{Depth: 9}, b)
+	r := newBitRules(boardSize, winLength, false)
+	m, info, ok := e.BestMove(context.Background(), r)
+	if !ok || m.Row != 2 || m.Col != 1 || info.Nodes != 0 {
+		t.Errorf("Ожидался ход книги 2,1 без перебора, получено %v, %+v", m, info)
+	}
+	r2 := r.Apply(m)
+	if _, info, ok := e.BestMove(context.Background(), r2); !ok || info.Nodes == 0 {
+		t.Errorf("Позиции нет в книге, но перебора не было: %+v", info)
+	}
+	if e.Name() != "minimax:9" {
+		t.Errorf("Книга изменила название движка: %s", e.Name())
+	}
+	if e := withBook(minimaxEngine{Depth: 9}, nil); e != (minimaxEngine{Depth: 9}) {
+		t.Errorf("Без книги движок изменён")
+	}
+}
+
+func TestGenerateBook(t *testing.T) {
+	r := newBitRules(boardSize, winLength, false)
+	entries, err := generateBook(r, 2, minimaxEngine{Depth: 9})
+	if err != nil || len(entries) != 10 {
+		t.Fatalf("Записей %d вместо 10", len(entries))
+	}
+	// С пустого поля все ходы ведут к ничьей; на угол отвечают только центром.
+	if len(entries[0].Moves) != 9 {
+		t.Errorf("Для пустого поля записано %d ходов", len(entries[0].Moves))
+	}
+	b := newBook(entries)
+	corner := r.Apply(Move{Row: 0, Col: 0, Symbol: Cross})
+	if moves := b.lookup(corner); len(moves) != 1 || moves[0].Move != "1,1" {
+		t.Errorf("На угол записаны ответы %v", moves)
+	}
+
+	var out bytes.Buffer
+	if err := runBook(&out, newBitRules(4, 3, false), 1, minimaxEngine{Depth: 3, Budget: 1 << 40}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "# classic-4x4-k3, 1 plies") || !strings.Contains(out.String(), "classic-4x4-k3 ..../..../..../.... X ") {
+		t.Errorf("Неверная книга 4×4:\n%s", out.String())
+	}
+	if err := runBook(&out, r, 1, &mctsEngine{Iterations: 10}); err == nil {
+		t.Errorf("Ожидалась ошибка для движка без перебора")
+	}
+}
+
+func TestBookMultiLayer(t *testing.T) {
+	// В записи многослойного поля нет пробелов, и она читается обратно.
+	r := variants[modeQubic].newRules()
+	e := bookEntryFor(r)
+	e.Moves = []BookMove{{formatMove(r, r.LegalMoves()[0]), 1}}
+	var out bytes.Buffer
+	writeBookText(&out, []BookEntry{e})
+	entries, err := parseBookText(&out)
+	if err != nil || len(entries) != 1 || newBook(entries).lookup(r) == nil {
+		t.Errorf("Запись многослойного поля не читается: %q (%v)", out.String(), err)
+	}
+}
+
+func TestBookVariantState(t *testing.T) {
+	// В ultimate и morris одно поле — разные позиции, и книга для них не строится и не читается.
+	for _, mode := range []string{modeUltimate, modeMorris} {
+		r := variants[mode].newRules()
+		if _, err := generateBook(r, 1, minimaxEngine{Depth: 1}); err == nil {
+			t.Errorf("%s: книга построена", mode)
+		}
+		e := bookEntryFor(r)
+		e.Moves = []BookMove{{formatMove(r, r.LegalMoves()[0]), 1}}
+		if moves := newBook([]BookEntry{e}).lookup(r); moves != nil {
+			t.Errorf("%s: найдены ходы книги %v", mode, moves)
+		}
+	}
+}
+
+func TestBookEngineClock(t *testing.T) {
+	// Книга не мешает часам ограничивать перебор.
+	b := newBook([]BookEntry{{Variant: "classic", Board: ".../.../...", ToMove: "X", Moves: []BookMove{{"2,1", 1}}}})
+	ft := &fakeTime{t: time.Unix(0, 0)}
+	c := newClock(TimeControl{PerMove: time.Second}, ft.now)
+	c.Start(Circle)
+
+	e, ok := timedEngine(withBook(minimaxEngine{Depth: 9}, b), c, Circle).(*bookEngine)
+	if !ok {
+		t.Fatal("Часы сняли книгу с движка")
+	}
+	if inner := e.Engine.(minimaxEngine); inner.Budget != 900*time.Millisecond {
+		t.Errorf("Перебор после книги получил время %v вместо 0.9s", inner.Budget)
+	}
+	if e.Book != b {
+		t.Errorf("Часы заменили книгу")
+	}
+	if m, _, ok := e.BestMove(context.Background(), newBitRules(boardSize, winLength, false)); !ok || m.Row != 2 || m.Col != 1 {
+		t.Errorf("Ожидался ход книги 2,1, получено %v", m)
+	}
+
+	q := withBook(&qEngine{Table: newQTable()}, b)
+	if got := timedEngine(q, c, Circle); got != q {
+		t.Errorf("Движок без перебора изменён")
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import "fmt"
+
+// ClassicRules — крестики-нолики на квадратном поле size×size, где побеждает
+// собравший k в ряд. В мизере собравший линию, наоборот, проигрывает.
+type ClassicRules struct {
+	Size, K int
+	Cells   []Player // Клетки поля построчно.
+	Turn    Player
+	Misere  bool
+	Lines   [][]Cell // Все отрезки длины K; общие для всех позиций партии.
+	Weights HeuristicWeights
+}
+
+// newClassicRules создаёт пустое поле, на котором первыми ходят крестики.
+// Позиции оцениваются с весами heuristicWeights.
+func newClassicRules(size, k int, misere bool) *ClassicRules {
+	return &ClassicRules{
+		Size:    size,
+		K:       k,
+		Cells:   make([]Player, size*size),
+		Turn:    Cross,
+		Misere:  misere,
+		Lines:   gridLines(size, size, k),
+		Weights: heuristicWeights,
+	}
+}
+
+// newClassic создаёт пустое поле для игры: в битовом представлении, если поле
+// помещается в маску, и в ClassicRules для полей больше 8×8.
+func newClassic(size, k int, misere bool) Rules {
+	if size*size <= maxBitboardCells {
+		return newBitRules(size, k, misere)
+	}
+	return newClassicRules(size, k, misere)
+}
+
+// classicFromBoard переводит классическое поле 3×3 в правила с ходом игрока turn.
+func classicFromBoard(b [boardSize][boardSize]Player, turn Player) *ClassicRules {
+	r := newClassicRules(boardSize, winLength, misere)
+	for i := 0; i < boardSize; i++ {
+		for j := 0; j < boardSize; j++ {
+			r.Cells[i*boardSize+j] = b[i][j]
+		}
+	}
+	r.Turn = turn
+	return r
+}
+
+// Name возвращает "classic", добавляя размер поля, если оно не 3×3, и пометку мизера.
+func (r *ClassicRules) Name() string {
+	name := modeClassic
+	if r.Size != boardSize || r.K != winLength {
+		name += fmt.Sprintf("-%dx%d-k%d", r.Size, r.Size, r.K)
+	}
+	if r.Misere {
+		name += "-misere"
+	}
+	return name
+}
+
+func (r *ClassicRules) ToMove() Player {
+	return r.Turn
+}
+
+func (r *ClassicRules) At(c Cell3) Player {
+	return r.Cells[c.Row*r.Size+c.Col]
+}
+
+// line возвращает владельца собранной линии и её клетки.
+func (r *ClassicRules) line() (Player, []Cell) {
+	return findLine(func(row, col int) Player { return r.Cells[row*r.Size+col] }, r.Size, r.Size, r.K)
+}
+
+func (r *ClassicRules) full() bool {
+	for _, p := range r.Cells {
+		if p == Empty {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *ClassicRules) Terminal() bool {
+	if p, _ := r.line(); p != Empty {
+		return true
+	}
+	return r.full()
+}
+
+func (r *ClassicRules) Winner() Player {
+	p, _ := r.line()
+	if p != Empty && r.Misere {
+		return other(p)
+	}
+	return p
+}
+
+func (r *ClassicRules) LegalMoves() []Move {
+	var moves []Move
+	if r.Terminal() {
+		return moves
+	}
+	for i, p := range r.Cells {
+		if p == Empty {
+			moves = append(moves, Move{Row: i / r.Size, Col: i % r.Size, Symbol: r.Turn})
+		}
+	}
+	return moves
+}
+
+func (r *ClassicRules) Apply(m Move) Rules {
+	next := *r
+	next.Cells = make([]Player, len(r.Cells))
+	copy(next.Cells, r.Cells)
+	next.Cells[m.Row*r.Size+m.Col] = r.Turn
+	next.Turn = other(r.Turn)
+	return &next
+}
+
+// Evaluate оценивает позицию эвристикой с точки зрения ходящего.
+// В мизере собирать линии невыгодно, поэтому оценка берётся с обратным знаком.
+func (r *ClassicRules) Evaluate() int {
+	if r.Misere {
+		return -r.heuristic(r.Turn)
+	}
+	return r.heuristic(r.Turn)
+}
+
+func (r *ClassicRules) Hints() RenderHints {
+	h := RenderHints{Layers: 1, Rows: r.Size, Cols: r.Size}
+	if _, cells := r.line(); cells != nil {
+		for _, c := range cells {
+			h.Line = append(h.Line, Cell3{0, c.Row, c.Col})
+		}
+	}
+	if r.Misere {
+		h.Note = "misere"
+	}
+	return h
+}
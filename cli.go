@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+)
+
+// runPlay ведёт текстовую партию: человек играет крестиками и вводит ходы построчно,
+// компьютер отвечает ноликами, выбирая ходы движком e.
+func runPlay(in io.Reader, out io.Writer, r Rules, e Engine) error {
+	scanner := bufio.NewScanner(in)
+	for !r.Terminal() {
+		if r.ToMove() == Circle {
+			m, info, ok := e.BestMove(context.Background(), r)
+			if !ok {
+				break
+			}
+			fmt.Fprintf(out, "computer: %s - %s (%s)\n", formatMove(r, m), explainMove(r, m, explainDepth(r)), formatSearchInfo(info))
+			r = r.Apply(m)
+			continue
+		}
+
+		fmt.Fprint(out, renderText(r))
+		fmt.Fprint(out, "your move: ")
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return err
+			}
+			return io.ErrUnexpectedEOF
+		}
+		m, err := parseMove(r, scanner.Text())
+		if err != nil {
+			fmt.Fprintln(out, err)
+			continue
+		}
+		r = r.Apply(m)
+	}
+
+	fmt.Fprint(out, renderText(r))
+	switch r.Winner() {
+	case Empty:
+		fmt.Fprintln(out, "It's a draw!")
+	case Circle:
+		fmt.Fprintln(out, "Computer wins")
+	default:
+		fmt.Fprintln(out, "You win")
+	}
+	return nil
+}
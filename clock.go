@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// clockMovesToGo — на сколько ещё ходов компьютер делит оставшееся время.
+	clockMovesToGo = 20
+	// clockReserve — доля времени на ход (1/clockReserve), оставляемая на задержки игрового цикла.
+	clockReserve = 10
+)
+
+// TimeControl — контроль времени партии. Нулевые поля не действуют:
+// Total — общее время каждого игрока, Increment — добавка за сделанный ход,
+// PerMove — предел времени на один ход.
+type TimeControl struct {
+	Total     time.Duration
+	Increment time.Duration
+	PerMove   time.Duration
+}
+
+// Enabled сообщает, задан ли хоть какой-то контроль времени.
+func (tc TimeControl) Enabled() bool {
+	return tc.Total > 0 || tc.PerMove > 0
+}
+
+// parseTimeControl разбирает контроль времени: "5m" — пять минут на партию,
+// "5m+3s" — с добавкой трёх секунд за ход, "10s/move" — не больше десяти секунд на ход.
+// Части можно сочетать через запятую: "5m+3s,30s/move". Пустая строка — игра без часов.
+func parseTimeControl(spec string) (TimeControl, error) {
+	var tc TimeControl
+	if spec == "" {
+		return tc, nil
+	}
+	for _, part := range strings.Split(spec, ",") {
+		if limit, ok := strings.CutSuffix(part, "/move"); ok {
+			d, err := time.ParseDuration(limit)
+			if err != nil || d <= 0 {
+				return tc, fmt.Errorf("неверное время на ход %q", limit)
+			}
+			tc.PerMove = d
+			continue
+		}
+		total, inc, hasInc := strings.Cut(part, "+")
+		d, err := time.ParseDuration(total)
+		if err != nil || d <= 0 {
+			return tc, fmt.Errorf("неверное время на партию %q", total)
+		}
+		tc.Total = d
+		if hasInc {
+			if tc.Increment, err = time.ParseDuration(inc); err != nil || tc.Increment < 0 {
+				return tc, fmt.Errorf("неверная добавка за ход %q", inc)
+			}
+		}
+	}
+	if tc.Increment > 0 && tc.Total == 0 {
+		return tc, fmt.Errorf("добавка за ход без времени на партию")
+	}
+	return tc, nil
+}
+
+// Clock — шахматные часы двух игроков. Идёт время только одного игрока, Running;
+// текущее время берётся из now, чтобы часы можно было проверять без ожидания.
+type Clock struct {
+	Control   TimeControl
+	Remaining map[Player]time.Duration // Общее время игроков без текущего хода.
+	Running   Player                   // Чьи часы идут; Empty — часы остановлены.
+
+	now       func() time.Time
+	moveStart time.Time
+	flagged   Player
+}
+
+// newClock создаёт остановленные часы с полным временем у обоих игроков.
+func newClock(tc TimeControl, now func() time.Time) *Clock {
+	return &Clock{
+		Control:   tc,
+		Remaining: map[Player]time.Duration{Cross: tc.Total, Circle: tc.Total},
+		now:       now,
+	}
+}
+
+// Start запускает часы игрока p.
+func (c *Clock) Start(p Player) {
+	c.Running = p
+	c.moveStart = c.now()
+}
+
+// Press заканчивает ход идущего игрока: списывает потраченное время, начисляет добавку
+// и запускает часы игрока next. Empty в next останавливает часы.
+func (c *Clock) Press(next Player) {
+	if p := c.Running; p != Empty {
+		if c.Left(p) <= 0 && c.flagged == Empty {
+			c.flagged = p
+		}
+		if c.Control.Total > 0 {
+			c.Remaining[p] -= c.now().Sub(c.moveStart)
+			c.Remaining[p] += c.Control.Increment
+		}
+	}
+	c.Start(next)
+}
+
+// Left возвращает время, оставшееся у игрока p на текущий ход.
+func (c *Clock) Left(p Player) time.Duration {
+	var spent time.Duration
+	if p == c.Running {
+		spent = c.now().Sub(c.moveStart)
+	}
+	left := time.Duration(1<<63 - 1)
+	if c.Control.Total > 0 {
+		left = c.Remaining[p] - spent
+	}
+	if c.Control.PerMove > 0 && c.Control.PerMove-spent < left {
+		left = c.Control.PerMove - spent
+	}
+	return left
+}
+
+// Flagged возвращает игрока, у которого кончилось время, или Empty.
+func (c *Clock) Flagged() Player {
+	if c.flagged == Empty && c.Running != Empty && c.Left(c.Running) <= 0 {
+		c.flagged = c.Running
+	}
+	return c.flagged
+}
+
+// Budget возвращает время на поиск хода игрока p: равную долю оставшегося времени
+// с добавкой, но не больше половины остатка и времени, оставшегося на ход, за вычетом запаса.
+func (c *Clock) Budget(p Player) time.Duration {
+	left := c.Left(p)
+	budget := left
+	if c.Control.Total > 0 {
+		var spent time.Duration
+		if p == c.Running {
+			spent = c.now().Sub(c.moveStart)
+		}
+		total := c.Remaining[p] - spent
+		budget = total/clockMovesToGo + c.Control.Increment
+		if budget > total/2 {
+			budget = total / 2
+		}
+		if budget > left {
+			budget = left
+		}
+	}
+	budget -= budget / clockReserve
+	if budget < time.Millisecond {
+		return time.Millisecond
+	}
+	return budget
+}
+
+// formatClock записывает оставшееся время как "м:сс", а последние десять секунд — с десятыми.
+func formatClock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	if d < 10*time.Second {
+		return fmt.Sprintf("%d.%d", d/time.Second, d%time.Second/(100*time.Millisecond))
+	}
+	d = d.Truncate(time.Second)
+	return fmt.Sprintf("%d:%02d", d/time.Minute, d%time.Minute/time.Second)
+}
+
+// budgeted реализуют движки, которым можно задать время на ход.
+type budgeted interface {
+	withBudget(d time.Duration) Engine
+}
+
+// withBudget ограничивает поиск временем d, но не дольше уже заданного.
+func (e minimaxEngine) withBudget(d time.Duration) Engine {
+	if e.Budget == 0 || d < e.Budget {
+		e.Budget = d
+	}
+	return e
+}
+
+// withBudget переводит движок на поиск по времени: число итераций часы не учитывает.
+func (e *mctsEngine) withBudget(d time.Duration) Engine {
+	if e.Iterations == 0 && e.Budget > 0 && e.Budget < d {
+		d = e.Budget
+	}
+	return &mctsEngine{Budget: d, Rand: e.Rand}
+}
+
+// timedEngine возвращает движок, укладывающийся во время на ход по часам игрока p.
+// Движки без поиска по времени возвращаются без изменений.
+func timedEngine(e Engine, c *Clock, p Player) Engine {
+	if b, ok := e.(budgeted); ok && c != nil {
+		return b.withBudget(c.Budget(p))
+	}
+	return e
+}
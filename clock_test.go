@@ -0,0 +1,182 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeTime — управляемый источник времени для часов.
+type fakeTime struct {
+	t time.Time
+}
+
+func (f *fakeTime) now() time.Time {
+	return f.t
+}
+
+func (f *fakeTime) advance(d time.Duration) {
+	f.t = f.t.Add(d)
+}
+
+func TestParseTimeControl(t *testing.T) {
+	tests := []struct {
+		spec string
+		want TimeControl
+	}{
+		{"", TimeControl{}},
+		{"5m", TimeControl{Total: 5 * time.Minute}},
+		{"5m+3s", TimeControl{Total: 5 * time.Minute, Increment: 3 * time.Second}},
+		{"10s/move", TimeControl{PerMove: 10 * time.Second}},
+		{"1m+1s,5s/move", TimeControl{Total: time.Minute, Increment: time.Second, PerMove: 5 * time.Second}},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeControl(tt.spec)
+		if err != nil || got != tt.want {
+			t.Errorf("parseTimeControl(%q) = %+v, %v; ожидалось %+v", tt.spec, got, err, tt.want)
+		}
+	}
+	for _, spec := range []string{"5", "-1m", "5m+x", "0s/move", "abc/move", "5m+-1s"} {
+		if _, err := parseTimeControl(spec); err == nil {
+			t.Errorf("parseTimeControl(%q): ожидалась ошибка", spec)
+		}
+	}
+}
+
+func TestClockIncrement(t *testing.T) {
+	ft := &fakeTime{t: time.Unix(0, 0)}
+	c := newClock(TimeControl{Total: time.Minute, Increment: 2 * time.Second}, ft.now)
+	c.Start(Cross)
+	ft.advance(10 * time.Second)
+	if left := c.Left(Cross); left != 50*time.Second {
+		t.Errorf("У крестиков осталось %v вместо 50s", left)
+	}
+	if left := c.Left(Circle); left != time.Minute {
+		t.Errorf("Часы ноликов идут, пока ходят крестики: %v", left)
+	}
+	c.Press(Circle)
+	if c.Remaining[Cross] != 52*time.Second || c.Running != Circle {
+		t.Errorf("После хода у крестиков %v, идут часы %v", c.Remaining[Cross], c.Running)
+	}
+	ft.advance(time.Minute)
+	if p := c.Flagged(); p != Circle {
+		t.Errorf("Время ноликов истекло, но Flagged() = %v", p)
+	}
+	// Флаг не снимается, даже если игрок успел сделать ход после падения.
+	c.Press(Cross)
+	if p := c.Flagged(); p != Circle {
+		t.Errorf("После хода Flagged() = %v", p)
+	}
+}
+
+func TestClockPerMove(t *testing.T) {
+	ft := &fakeTime{t: time.Unix(0, 0)}
+	c := newClock(TimeControl{PerMove: 5 * time.Second}, ft.now)
+	c.Start(Cross)
+	ft.advance(4 * time.Second)
+	c.Press(Circle)
+	ft.advance(4 * time.Second)
+	if c.Flagged() != Empty || c.Left(Circle) != time.Second || c.Left(Cross) != 5*time.Second {
+		t.Errorf("Предел на ход считается неверно: %v %v", c.Left(Circle), c.Left(Cross))
+	}
+	ft.advance(2 * time.Second)
+	if p := c.Flagged(); p != Circle {
+		t.Errorf("Ход дольше предела, но Flagged() = %v", p)
+	}
+}
+
+func TestClockStopped(t *testing.T) {
+	ft := &fakeTime{t: time.Unix(0, 0)}
+	c := newClock(TimeControl{Total: time.Second}, ft.now)
+	c.Start(Cross)
+	c.Press(Empty)
+	ft.advance(time.Hour)
+	if c.Flagged() != Empty || c.Left(Cross) != time.Second {
+		t.Errorf("Остановленные часы идут")
+	}
+}
+
+func TestClockBudget(t *testing.T) {
+	ft := &fakeTime{t: time.Unix(0, 0)}
+	c := newClock(TimeControl{Total: time.Minute, Increment: time.Second}, ft.now)
+	c.Start(Circle)
+	// Доля остатка с добавкой за вычетом запаса: (60s/20 + 1s) * 0.9.
+	if b := c.Budget(Circle); b != 3600*time.Millisecond {
+		t.Errorf("Время на ход %v вместо 3.6s", b)
+	}
+	ft.advance(58 * time.Second)
+	// При двух секундах остатка — не больше половины.
+	if b := c.Budget(Circle); b != 900*time.Millisecond {
+		t.Errorf("Время на ход %v вместо 0.9s", b)
+	}
+
+	c = newClock(TimeControl{PerMove: 2 * time.Second}, ft.now)
+	c.Start(Circle)
+	if b := c.Budget(Circle); b != 1800*time.Millisecond {
+		t.Errorf("Время на ход %v вместо 1.8s", b)
+	}
+}
+
+func TestTimedEngine(t *testing.T) {
+	ft := &fakeTime{t: time.Unix(0, 0)}
+	c := newClock(TimeControl{PerMove: time.Second}, ft.now)
+	c.Start(Circle)
+	if e := timedEngine(minimaxEngine{Depth: 9}, c, Circle).(minimaxEngine); e.Budget != 900*time.Millisecond || e.Depth != 9 {
+		t.Errorf("minimax получил %+v", e)
+	}
+	if e := timedEngine(minimaxEngine{Depth: 9, Budget: 100 * time.Millisecond}, c, Circle).(minimaxEngine); e.Budget != 100*time.Millisecond {
+		t.Errorf("Часы увеличили время minimax: %v", e.Budget)
+	}
+	if e := timedEngine(&mctsEngine{Iterations: 100}, c, Circle).(*mctsEngine); e.Budget != 900*time.Millisecond || e.Iterations != 0 {
+		t.Errorf("mcts получил %+v", e)
+	}
+	q := &qEngine{Table: newQTable()}
+	if e := timedEngine(q, c, Circle); e != q {
+		t.Errorf("Движок без перебора изменён")
+	}
+	if e := timedEngine(minimaxEngine{Depth: 3}, nil, Circle).(minimaxEngine); e.Budget != 0 {
+		t.Errorf("Без часов время на ход задано: %v", e.Budget)
+	}
+}
+
+func TestFormatClock(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		5*time.Minute + 3*time.Second + 400*time.Millisecond: "5:03",
+		10 * time.Second:                     "0:10",
+		9*time.Second + 470*time.Millisecond: "9.4",
+		-time.Second:                         "0.0",
+	} {
+		if got := formatClock(d); got != want {
+			t.Errorf("formatClock(%v) = %q, ожидалось %q", d, got, want)
+		}
+	}
+}
+
+func TestLossOnTime(t *testing.T) {
+	// Игрок, у которого кончилось время, проигрывает, даже если ход за компьютером.
+	oldControl, oldVs := timeControl, vsComputer
+	timeControl, vsComputer = TimeControl{Total: time.Minute}, true
+	defer func() {
+		timeControl, vsComputer = oldControl, oldVs
+		resetGame()
+	}()
+	resetGame()
+	ft := &fakeTime{t: time.Unix(0, 0)}
+	clock.now = ft.now
+	clock.Start(game.ToMove())
+	playMove(game.LegalMoves()[0])
+	if clock.Running != Circle {
+		t.Fatalf("После хода крестиков идут часы %v", clock.Running)
+	}
+	undoMove()
+	if len(history) != 1 {
+		t.Errorf("В партии с часами ход отменён")
+	}
+	ft.advance(2 * time.Minute)
+	checkClock()
+	if !gameOver || winner != Cross || winnerString != "You win (on time)" {
+		t.Errorf("Проигрыш по времени не засчитан: %q", winnerString)
+	}
+	if clock.Running != Empty || pending != nil {
+		t.Errorf("Часы или поиск не остановлены")
+	}
+}
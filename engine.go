@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Engine выбирает ход в позиции любого варианта игры.
+type Engine interface {
+	// Name возвращает описание движка в том же виде, в каком оно задаётся флагом -engine.
+	Name() string
+	// BestMove возвращает ход для ходящего и сведения о поиске; ok ложно, если ходов нет
+	// или поиск прерван отменой ctx.
+	BestMove(ctx context.Context, r Rules) (m Move, info SearchInfo, ok bool)
+}
+
+// minimaxEngine перебирает позиции альфа-бета поиском. Если задан Budget, поиск идёт
+// итеративным углублением до глубины Depth, пока не истечёт время на ход.
+// Ходы из начальной позиции делятся между Workers горутинами; выбранный ход
+// от числа горутин не зависит, поэтому в названии движка оно не указывается.
+// Если задан Ties, ход выбирается им среди всех ходов с лучшей оценкой,
+// а если задан Observer, ему сообщается итог каждой завершённой глубины.
+type minimaxEngine struct {
+	Depth    int
+	Budget   time.Duration
+	Workers  int
+	Ties     *tieBreaker
+	Observer searchObserver
+}
+
+// SearchProgress — итог завершённой глубины перебора: лучший ход, его оценка
+// для ходящего, главный вариант, начинающийся с этого хода, и сведения о поиске.
+type SearchProgress struct {
+	Move  Move
+	Score int
+	PV    []Move
+	Info  SearchInfo
+}
+
+// searchObserver получает итоги перебора по мере углубления, например чтобы
+// выводить их внешней программе.
+type searchObserver interface {
+	observe(SearchProgress)
+}
+
+func (e minimaxEngine) Name() string {
+	if e.Budget > 0 {
+		return fmt.Sprintf("minimax:%d:%s", e.Depth, e.Budget)
+	}
+	return fmt.Sprintf("minimax:%d", e.Depth)
+}
+
+// BestMove сначала ищет выигрыш непрерывными угрозами, если вариант это умеет,
+// и переходит к обычному перебору, если такого выигрыша нет. Выигрыш угрозами
+// единственный, поэтому выбор среди равных ходов применяется только к перебору.
+// Оба поиска укладываются в общее время на ход.
+func (e minimaxEngine) BestMove(ctx context.Context, r Rules) (Move, SearchInfo, bool) {
+	start := time.Now()
+	var deadline time.Time
+	if e.Budget > 0 {
+		deadline = start.Add(e.Budget)
+	}
+	vcfNodes := 0
+	if fw, ok := r.(forcedWinner); ok {
+		vcfCtx := ctx
+		if e.Budget > 0 {
+			var cancel context.CancelFunc
+			vcfCtx, cancel = context.WithDeadline(ctx, deadline)
+			defer cancel()
+		}
+		seq, nodes, found := fw.ForcedWin(vcfCtx)
+		if found {
+			info := SearchInfo{Depth: len(seq), Nodes: nodes, Elapsed: time.Since(start)}
+			if e.Observer != nil {
+				e.Observer.observe(SearchProgress{Move: seq[0], Score: winScore, PV: seq, Info: info})
+			}
+			return seq[0], info, true
+		}
+		vcfNodes = nodes
+	}
+
+	if e.Budget > 0 {
+		var onDepth func(Move, int, SearchInfo)
+		if e.Observer != nil {
+			onDepth = func(m Move, val int, info SearchInfo) {
+				pv := principalVariation(ctx, deadline, r, m, info.Depth)
+				e.Observer.observe(SearchProgress{Move: m, Score: val, PV: pv, Info: info})
+			}
+		}
+		m, val, info, ok := deepen(ctx, r, e.Depth, e.Workers, time.Until(deadline), onDepth)
+		if ok {
+			m = breakTie(ctx, deadline, e.Ties, r, m, info.Depth, val)
+		}
+		info.Nodes += vcfNodes
+		info.Elapsed = time.Since(start)
+		return m, info, ok
+	}
+
+	moves := r.LegalMoves()
+	if len(moves) == 0 {
+		return Move{}, SearchInfo{}, false
+	}
+	s := searcher{ctx: ctx}
+	m, val := s.rootParallel(r, moves, e.Depth, e.Workers)
+	if !s.aborted {
+		m = breakTie(ctx, time.Time{}, e.Ties, r, m, e.Depth, val)
+	}
+	info := SearchInfo{Depth: e.Depth, Nodes: s.nodes + vcfNodes, Elapsed: time.Since(start)}
+	if e.Observer != nil && !s.aborted {
+		pv := principalVariation(ctx, time.Time{}, r, m, e.Depth)
+		e.Observer.observe(SearchProgress{Move: m, Score: val, PV: pv, Info: info})
+	}
+	return m, info, !s.aborted
+}
+
+// defaultIterations — число итераций MCTS, если оно не указано.
+const defaultIterations = 10000
+
+// parseEngine разбирает описание движка: "minimax", "minimax:4", "minimax:500ms",
+// "minimax:4:500ms", "mcts", "mcts:20000", "mcts:500ms", "q" — агент Q-обучения
+// или "random" — случайные ходы.
+// Если глубина не указана, minimax ищет на глубину depth варианта.
+func parseEngine(spec string, depth int) (Engine, error) {
+	name, param, hasParam := strings.Cut(spec, ":")
+	switch name {
+	case "minimax":
+		e := minimaxEngine{Depth: depth}
+		if !hasParam {
+			return e, nil
+		}
+		d, budget, hasBudget := strings.Cut(param, ":")
+		if n, err := strconv.Atoi(d); err == nil && n > 0 {
+			e.Depth = n
+		} else if t, err := time.ParseDuration(d); err == nil && t > 0 && !hasBudget {
+			e.Budget = t
+			return e, nil
+		} else {
+			return nil, fmt.Errorf("неверная глубина перебора %q", d)
+		}
+		if hasBudget {
+			t, err := time.ParseDuration(budget)
+			if err != nil || t <= 0 {
+				return nil, fmt.Errorf("неверное время на ход %q", budget)
+			}
+			e.Budget = t
+		}
+		return e, nil
+	case "mcts":
+		if !hasParam {
+			return &mctsEngine{Iterations: defaultIterations, Rand: newEngineRand()}, nil
+		}
+		if n, err := strconv.Atoi(param); err == nil && n > 0 {
+			return &mctsEngine{Iterations: n, Rand: newEngineRand()}, nil
+		}
+		if d, err := time.ParseDuration(param); err == nil && d > 0 {
+			return &mctsEngine{Budget: d, Rand: newEngineRand()}, nil
+		}
+		return nil, fmt.Errorf("неверное число итераций или время %q", param)
+	case "q":
+		t, err := sharedQTable()
+		if err != nil {
+			return nil, err
+		}
+		return &qEngine{Table: t}, nil
+	case "random":
+		if hasParam {
+			return nil, fmt.Errorf("у движка random нет параметров: %q", spec)
+		}
+		return newRandomEngine(), nil
+	}
+	return nil, fmt.Errorf("неизвестный движок %q", spec)
+}
+
+// difficulty — уровень сложности компьютера: время на ход и предельная глубина перебора.
+// Нулевая MaxDepth означает глубину варианта.
+type difficulty struct {
+	Budget   time.Duration
+	MaxDepth int
+}
+
+// difficulties — уровни, доступные через флаг -difficulty.
+var difficulties = map[string]difficulty{
+	"easy":   {Budget: 100 * time.Millisecond, MaxDepth: 2},
+	"medium": {Budget: 300 * time.Millisecond, MaxDepth: 4},
+	"hard":   {Budget: time.Second},
+}
+
+// newEngine создаёт движок по описанию spec, а если оно пустое — по уровню сложности level.
+// depth — глубина перебора варианта; перебор ведут searchWorkers горутин,
+// а равные ходы выбираются по стилю tieStyle своим для каждого движка генератором. Перед перебором движок смотрит
+// в дебютную книгу openingBook; агент Q-обучения играет только по своей таблице.
+func newEngine(spec, level string, depth int) (Engine, error) {
+	if spec != "" {
+		e, err := parseEngine(spec, depth)
+		switch m := e.(type) {
+		case minimaxEngine:
+			m.Workers = searchWorkers
+			m.Ties = newTies()
+			return withBook(m, openingBook), nil
+		case *qEngine:
+			return m, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		return withBook(e, openingBook), nil
+	}
+	d, ok := difficulties[level]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный уровень сложности %q", level)
+	}
+	if d.MaxDepth > 0 {
+		depth = min(depth, d.MaxDepth)
+	}
+	return withBook(minimaxEngine{Depth: depth, Budget: d.Budget, Workers: searchWorkers, Ties: newTies()}, openingBook), nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import "context"
+
+// Reason — причина, по которой ход лучший, для режима обучения.
+type Reason string
+
+const (
+	reasonWin       Reason = "immediate win"
+	reasonBlock     Reason = "block immediate loss"
+	reasonFork      Reason = "create a fork"
+	reasonBlockFork Reason = "block a fork"
+	reasonForcedWin Reason = "forced win"
+	reasonCenter    Reason = "take center"
+	reasonCorner    Reason = "take corner"
+	reasonAllDraw   Reason = "all moves draw"
+	reasonAllLose   Reason = "all moves lose"
+	reasonBest      Reason = "best by search"
+)
+
+// threatBoard реализуют варианты «k в ряд», в которых можно говорить об угрозах и вилках.
+type threatBoard interface {
+	// threatCells возвращает клетки, каждая из которых сразу собирает линию игрока p.
+	threatCells(p Player) []Cell
+	// forkCells возвращает клетки, поставив фишку в которую, игрок p получает две угрозы.
+	forkCells(p Player) []Cell
+	// isMisere сообщает, что собравший линию проигрывает: угрозы в мизере работают наоборот.
+	isMisere() bool
+}
+
+func (r *ClassicRules) isMisere() bool {
+	return r.Misere
+}
+
+func (b *BitRules) isMisere() bool {
+	return b.Misere
+}
+
+// forkCells возвращает клетки, ход в которые даёт игроку p вилку.
+func (r *ClassicRules) forkCells(p Player) []Cell {
+	var cells []Cell
+	for i, cell := range r.Cells {
+		if cell != Empty {
+			continue
+		}
+		next := *r
+		next.Cells = make([]Player, len(r.Cells))
+		copy(next.Cells, r.Cells)
+		next.Cells[i] = p
+		if len(next.threatCells(p)) >= 2 {
+			cells = append(cells, Cell{i / r.Size, i % r.Size})
+		}
+	}
+	return cells
+}
+
+func (b *BitRules) threatCells(p Player) []Cell {
+	return b.Classic().threatCells(p)
+}
+
+func (b *BitRules) forkCells(p Player) []Cell {
+	return b.Classic().forkCells(p)
+}
+
+// hasCell сообщает, есть ли среди клеток cells клетка хода m.
+func hasCell(cells []Cell, m Move) bool {
+	for _, c := range cells {
+		if c.Row == m.Row && c.Col == m.Col {
+			return true
+		}
+	}
+	return false
+}
+
+// explainDepth подбирает глубину перебора для объяснения хода: на поле 3×3
+// партия просчитывается до конца, в остальных вариантах — на два полухода.
+func explainDepth(r Rules) int {
+	moves := len(r.LegalMoves())
+	if _, ok := r.(threatBoard); ok && moves <= boardSize*boardSize {
+		return moves
+	}
+	return 2
+}
+
+// moveValues оценивает каждый допустимый ход перебором на глубину depth с полным окном,
+// чтобы оценки разных ходов можно было сравнивать между собой. exact сообщает, что
+// перебор везде дошёл до конца партии и оценки точные, а не эвристические.
+// После отмены ctx возвращается ok == false, и оценки использовать нельзя.
+func moveValues(ctx context.Context, r Rules, depth int) (moves []Move, vals []int, exact, ok bool) {
+	s := searcher{ctx: ctx}
+	moves = r.LegalMoves()
+	vals = make([]int, len(moves))
+	for i, m := range moves {
+		vals[i] = -s.search(r.Apply(m), depth-1, -inf, inf)
+		if s.aborted {
+			return nil, nil, false, false
+		}
+	}
+	return moves, vals, !s.cutoff, true
+}
+
+// explain объясняет, чем хорош ход m в позиции r. Все ходы сначала оцениваются
+// перебором на глубину depth, и тактическая причина — защита, вилка, защита от вилки,
+// центр или угол — называется, только если перебор подтверждает, что ход лучший:
+// защита должна спасать от проигрыша, а защита от вилки, центр и угол — быть лучше
+// других ходов. Иначе причина берётся из оценок: доказанный выигрыш, ничья или
+// проигрыш при любом ходе. Ничья утверждается, только если перебор дошёл до конца
+// всех партий; выигрыш и проигрыш перебор находит лишь в доигранных партиях.
+// Перебор прерывается отменой ctx, и тогда ok == false.
+func explain(ctx context.Context, r Rules, m Move, depth int) (reason Reason, ok bool) {
+	mover, opp := r.ToMove(), other(r.ToMove())
+	next := r.Apply(m)
+	if next.Terminal() && next.Winner() == mover {
+		return reasonWin, true
+	}
+
+	moves, vals, exact, ok := moveValues(ctx, r, depth)
+	if !ok {
+		return "", false
+	}
+	val, bestVal, worstVal := 0, -inf, inf
+	allDraw, allLose := true, true
+	for i, v := range vals {
+		if moves[i] == m {
+			val = v
+		}
+		bestVal, worstVal = max(bestVal, v), min(worstVal, v)
+		allDraw = allDraw && v == 0
+		allLose = allLose && v <= -winScore
+	}
+	// best — перебор подтверждает, что ход лучший; better — и что другие ходы хуже.
+	best := val == bestVal
+	better := best && worstVal < val
+
+	tb, tactical := r.(threatBoard)
+	tactical = tactical && !tb.isMisere()
+	if tactical && best {
+		threats := tb.threatCells(opp)
+		if hasCell(threats, m) && val > -winScore {
+			return reasonBlock, true
+		}
+		if nt, ok := next.(threatBoard); ok {
+			if len(nt.threatCells(mover)) >= 2 {
+				return reasonFork, true
+			}
+			// Пока соперник грозит выиграть сразу, его вилки уже не важны.
+			if forks := tb.forkCells(opp); better && len(threats) == 0 && len(forks) > 0 && len(nt.forkCells(opp)) < len(forks) {
+				return reasonBlockFork, true
+			}
+		}
+	}
+
+	switch {
+	case val >= winScore:
+		return reasonForcedWin, true
+	case allDraw && exact:
+		return reasonAllDraw, true
+	case allLose:
+		return reasonAllLose, true
+	}
+
+	if tactical && better {
+		h := r.Hints()
+		lastRow, lastCol := h.Rows-1, h.Cols-1
+		if m.Row >= lastRow/2 && m.Row <= h.Rows/2 && m.Col >= lastCol/2 && m.Col <= h.Cols/2 {
+			return reasonCenter, true
+		}
+		if (m.Row == 0 || m.Row == lastRow) && (m.Col == 0 || m.Col == lastCol) {
+			return reasonCorner, true
+		}
+	}
+	return reasonBest, true
+}
+
+// explainMove объясняет ход m, как explain, перебором без ограничения времени.
+func explainMove(r Rules, m Move, depth int) Reason {
+	reason, _ := explain(context.Background(), r, m, depth)
+	return reason
+}
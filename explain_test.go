@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExplainBestMove(t *testing.T) {
+	// Объясняется ход, который выбирает полный перебор.
+	tests := []struct {
+		name string
+		r    *ClassicRules
+		want Reason
+	}{
+		{"выигрыш", classicFromText(3, Cross, "XX.", "OO.", "..."), reasonWin},
+		{"защита", classicFromText(3, Circle, "XX.", ".O.", "..."), reasonBlock},
+		{"вилка", classicFromText(3, Cross, "XO.", ".X.", "..O"), reasonFork},
+		{"защита от вилки", classicFromText(3, Circle, "X..", ".O.", "..X"), reasonBlockFork},
+		{"центр", classicFromText(3, Circle, "X..", "...", "..."), reasonCenter},
+		{"угол", classicFromText(3, Circle, "...", ".X.", "..."), reasonCorner},
+		{"любой ход ничья", classicFromText(3, Cross, "...", "...", "..."), reasonAllDraw},
+	}
+	for _, tt := range tests {
+		m, _, ok := bestMove(tt.r, boardSize*boardSize)
+		if !ok {
+			t.Fatalf("%s: нет хода", tt.name)
+		}
+		if got := explainMove(tt.r, m, explainDepth(tt.r)); got != tt.want {
+			t.Errorf("%s: ход %v объяснён как %q, ожидалось %q", tt.name, m, got, tt.want)
+		}
+	}
+}
+
+func TestExplainMoveBySearch(t *testing.T) {
+	// Без тактики причина берётся из оценок перебора.
+	r := classicFromText(3, Circle, "XX.", "XO.", "..O")
+	if got := explainMove(r, Move{Row: 1, Col: 2, Symbol: Circle}, explainDepth(r)); got != reasonAllLose {
+		t.Errorf("Против двух угроз ожидалось %q, получено %q", reasonAllLose, got)
+	}
+	r = classicFromText(3, Cross, "XO.", "...", "...")
+	m := Move{Row: 1, Col: 1, Symbol: Cross}
+	if got := explainMove(r, m, explainDepth(r)); got != reasonForcedWin {
+		t.Errorf("Ожидалось %q, получено %q", reasonForcedWin, got)
+	}
+	// Битовое представление объясняется так же, как обычное.
+	if got, want := explainMove(bitRulesFromClassic(r), m, explainDepth(r)), explainMove(r, m, explainDepth(r)); got != want {
+		t.Errorf("BitRules: %q, ClassicRules: %q", got, want)
+	}
+}
+
+func TestExplainMisere(t *testing.T) {
+	// В мизере угрозы не защищают: закрыть линию соперника — не причина хода.
+	r := newClassicRules(boardSize, winLength, true)
+	r.Cells = classicFromText(3, Circle, "XX.", ".O.", "...").Cells
+	r.Turn = Circle
+	if got := explainMove(r, Move{Row: 0, Col: 2, Symbol: Circle}, explainDepth(r)); got == reasonBlock {
+		t.Errorf("В мизере ход объяснён защитой")
+	}
+}
+
+func TestExplainOtherVariant(t *testing.T) {
+	// В вариантах без угроз объяснение опирается только на перебор.
+	r := variants[modeGravity].newRules()
+	m, _, _ := bestMove(r, 2)
+	if got := explainMove(r, m, explainDepth(r)); got != reasonBest {
+		t.Errorf("Неожиданное объяснение первого хода: %q", got)
+	}
+	// Ничью доказывает только перебор до конца всех партий.
+	if _, _, exact, _ := moveValues(context.Background(), newClassicRules(5, 4, false), 2); exact {
+		t.Error("Перебор на два полухода на пустом поле 5×5 назван точным")
+	}
+	r = newClassicRules(boardSize, winLength, false)
+	if _, _, exact, _ := moveValues(context.Background(), r, explainDepth(r)); !exact {
+		t.Error("Полный перебор поля 3×3 назван неточным")
+	}
+}
+
+func TestExplainWorseMove(t *testing.T) {
+	// Центр и угол не причина, если перебор показал, что ход хуже других.
+	r := classicFromText(3, Circle, "X..", "...", "...")
+	if got := explainMove(r, Move{Row: 0, Col: 2, Symbol: Circle}, explainDepth(r)); got == reasonCorner {
+		t.Errorf("Проигрывающий угол объяснён как %q", got)
+	}
+	r = classicFromText(3, Cross, "XOX", "...", "O..")
+	if got := explainMove(r, Move{Row: 1, Col: 1, Symbol: Cross}, explainDepth(r)); got == reasonCenter {
+		t.Errorf("Центр, упускающий выигрыш, объяснён как %q", got)
+	}
+	// Закрыть одну из двух угроз — не защита: проигрыш всё равно неизбежен.
+	r = classicFromText(3, Circle, "XX.", "XO.", "..O")
+	if got := explainMove(r, Move{Row: 0, Col: 2, Symbol: Circle}, explainDepth(r)); got == reasonBlock {
+		t.Errorf("Проигрывающий ход объяснён защитой")
+	}
+}
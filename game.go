@@ -1,24 +1,42 @@
 package main
 
 import (
+	"context"
 	"image/color"
+	"log"
 	"math"
 	"os"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/hajimehoshi/ebiten"
 	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/hajimehoshi/ebiten/inpututil"
 )
 
 const (
 	screenWidth  = 350
 	screenHeight = 350
 	boardSize    = 3
-	cellSize     = screenWidth / boardSize
 	lineWidth    = 2
+	winLength    = boardSize
+	winAnimTicks = 30
 )
 
 type Player int
 
+// Режимы игры, выбираемые флагом -mode.
+const (
+	modeClassic  = "classic"
+	modeUltimate = "ultimate"
+	modeQubic    = "qubic"
+	modeGravity  = "gravity"
+	modeMorris   = "morris"
+	modeWild     = "wild"
+	modePuzzle   = "puzzle"
+)
+
 const (
 	Empty Player = iota
 	Circle
@@ -26,101 +44,66 @@ const (
 )
 
 var (
-	board        [boardSize][boardSize]Player
-	currentTurn  = Circle
-	winner       = Empty
-	winnerString string
-	gameOver     = false
-	highlighted  = false
-	bestMoveRow  = -1
-	bestMoveCol  = -1
+	gameMode      = modeClassic
+	misere        = false
+	classicSize   = boardSize
+	classicK      = winLength
+	engineSpec    = ""
+	level         = "hard"
+	searchWorkers = runtime.NumCPU()
+	vsComputer    = false
+	lastSearch    SearchInfo
+	currentTurn   = Circle
+	winner        = Empty
+	winAnimTick   = 0
+	winnerString  string
+	gameOver      = false
+	moveCount     = 0
+	gameStart     time.Time
+	showStats     = false
+	statsRecords  []GameRecord
+	game          Rules
+	aiSide        = Empty
+	ai            Engine
+	hint          *Move
+	explanation   string
+	selected      *Cell
+	chosenSymbol  = Cross
+	history       []Rules
+	played        []Move
+	report        []MoveAnalysis
+	analysing     *backgroundAnalysis // Разбор партии, пока он идёт.
+	showReport    = false
+	puzzleTitle   string
+	timeControl   TimeControl
+	clock         *Clock
 )
 
-type MoveScore struct {
-	move  [2]int
-	score int
-}
-
-func FindBestMove() {
-	bestVal := math.MinInt32
-	var moveSequence []MoveScore
-
-	for i := 0; i < boardSize; i++ {
-		for j := 0; j < boardSize; j++ {
-			if board[i][j] == Empty {
-				board[i][j] = Circle
-				moveVal := minimax(board, 0, false, &moveSequence)
-				board[i][j] = Empty
-
-				if moveVal > bestVal {
-					bestMoveRow, bestMoveCol = i, j
-					bestVal = moveVal
-				}
-			}
-		}
-	}
-
-}
-
-func minimax(board [boardSize][boardSize]Player, depth int, isMaximizing bool, moveSequence *[]MoveScore) int {
-	score := evaluate(board)
-
-	if score != -2 {
-		return score
+// Цвета клеток поля.
+var (
+	circleColor   = color.RGBA{36, 36, 36, 255}
+	crossColor    = color.RGBA{65, 65, 65, 255}
+	hintColor     = color.RGBA{255, 0, 0, 255}
+	activeColor   = color.RGBA{255, 255, 180, 255}
+	selectedColor = color.RGBA{255, 200, 0, 255}
+	previewColor  = color.RGBA{120, 150, 230, 255}
+	markedColors  = map[Player]color.Color{
+		Circle: color.RGBA{170, 170, 230, 255},
+		Cross:  color.RGBA{230, 170, 170, 255},
 	}
+)
 
-	if isMaximizing {
-		best := math.MinInt32
-
-		for i := 0; i < boardSize; i++ {
-			for j := 0; j < boardSize; j++ {
-				if board[i][j] == Empty {
-					board[i][j] = Circle
-					moveVal := minimax(board, depth+1, !isMaximizing, moveSequence)
-					board[i][j] = Empty
-
-					moveScore := MoveScore{
-						move:  [2]int{j, i},
-						score: moveVal,
-					}
-					*moveSequence = append(*moveSequence, moveScore)
-
-					best = max(best, moveVal)
-				}
-			}
-		}
-
-		return best
-	} else {
-		best := math.MaxInt32
-
-		for i := 0; i < boardSize; i++ {
-			for j := 0; j < boardSize; j++ {
-				if board[i][j] == Empty {
-					board[i][j] = Cross
-					moveVal := minimax(board, depth+1, !isMaximizing, moveSequence)
-					board[i][j] = Empty
-
-					moveScore := MoveScore{
-						move:  [2]int{j, i},
-						score: moveVal,
-					}
-					*moveSequence = append(*moveSequence, moveScore)
-
-					best = min(best, moveVal)
-				}
-			}
-		}
-
-		return best
+// evaluate оценивает позицию с точки зрения ноликов: 1 — победа, -1 — поражение,
+// 0 — ничья, -2 — партия не окончена. В мизере собравший линию проигрывает.
+func evaluate(board [boardSize][boardSize]Player) int {
+	crossLine, circleLine := checkWin(board, Cross), checkWin(board, Circle)
+	if misere {
+		crossLine, circleLine = circleLine, crossLine
 	}
 
-}
-
-func evaluate(board [boardSize][boardSize]Player) int {
-	if checkWin(board, Cross) {
+	if crossLine {
 		return -1
-	} else if checkWin(board, Circle) {
+	} else if circleLine {
 		return 1
 	}
 
@@ -161,6 +144,14 @@ func checkWin(board [boardSize][boardSize]Player, player Player) bool {
 	return false
 }
 
+// other возвращает соперника игрока p.
+func other(p Player) Player {
+	if p == Circle {
+		return Cross
+	}
+	return Circle
+}
+
 func max(x, y int) int {
 	if x > y {
 		return x
@@ -175,139 +166,595 @@ func min(x, y int) int {
 	return y
 }
 
-func isBoardFull() bool {
-	for i := 0; i < boardSize; i++ {
-		for j := 0; j < boardSize; j++ {
-			if board[i][j] == Empty {
-				return false
+// Cell задаёт клетку поля.
+type Cell struct {
+	Row, Col int
+}
+
+// lineDirections перечисляет направления линий: горизонталь, вертикаль и две диагонали.
+var lineDirections = [4][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+
+// findLine ищет k одинаковых непустых фишек подряд на поле rows×cols.
+// Возвращает владельца линии и её клетки по порядку или Empty, если линии нет.
+func findLine(at func(row, col int) Player, rows, cols, k int) (Player, []Cell) {
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			p := at(r, c)
+			if p == Empty {
+				continue
+			}
+			for _, d := range lineDirections {
+				endR, endC := r+d[0]*(k-1), c+d[1]*(k-1)
+				if endR < 0 || endR >= rows || endC < 0 || endC >= cols {
+					continue
+				}
+				cells := make([]Cell, 0, k)
+				for n := 0; n < k && at(r+d[0]*n, c+d[1]*n) == p; n++ {
+					cells = append(cells, Cell{r + d[0]*n, c + d[1]*n})
+				}
+				if len(cells) == k {
+					return p, cells
+				}
 			}
 		}
 	}
-	return true
+	return Empty, nil
 }
 
-func checkWinner() Player {
-	for i := 0; i < boardSize; i++ {
-		if board[i][0] == board[i][1] && board[i][1] == board[i][2] && board[i][0] != Empty {
-			return board[i][0]
-		}
-		if board[0][i] == board[1][i] && board[1][i] == board[2][i] && board[0][i] != Empty {
-			return board[0][i]
-		}
+func resetGame() {
+	currentTurn = Cross
+	winner = Empty
+	winAnimTick = 0
+	winnerString = ""
+	gameOver = false
+	moveCount = 0
+	gameStart = time.Now()
+
+	cancelSearch()
+	searched = nil
+	history = nil
+	played = nil
+	if analysing != nil {
+		analysing.cancel()
+		analysing = nil
+	}
+	report = nil
+	showReport = false
+
+	v := variants[gameMode]
+	game = v.newRules()
+	aiSide = v.aiSide
+	if aiSide == Empty && vsComputer {
+		aiSide = Circle
+	}
+	if gameMode == modePuzzle {
+		aiSide = other(game.ToMove())
+		puzzleTitle = puzzles.Current().Title()
+	}
+	lastSearch = SearchInfo{}
+	if e, err := newEngine(engineSpec, level, v.depth); err == nil {
+		ai = e
+	} else {
+		log.Println(err)
+		ai = minimaxEngine{Depth: v.depth}
+	}
+	hint = nil
+	explanation = ""
+	selected = nil
+
+	clock = nil
+	if timeControl.Enabled() {
+		clock = newClock(timeControl, time.Now)
+		clock.Start(game.ToMove())
 	}
+}
 
-	if board[0][0] == board[1][1] && board[1][1] == board[2][2] && board[0][0] != Empty {
-		return board[0][0]
+// playMove делает ход в текущей партии и проверяет, не окончена ли она.
+// Идущий поиск подсказки для прежней позиции отменяется.
+func playMove(m Move) {
+	cancelSearch()
+	if gameMode == modePuzzle && game.ToMove() != aiSide && !puzzles.answer(game, m) {
+		failPuzzle()
+		return
 	}
-	if board[0][2] == board[1][1] && board[1][1] == board[2][0] && board[0][2] != Empty {
-		return board[0][2]
+	history = append(history, game)
+	played = append(played, m)
+	game = game.Apply(m)
+	currentTurn = game.ToMove()
+	moveCount++
+	if clock != nil {
+		if game.Terminal() {
+			clock.Press(Empty)
+		} else {
+			clock.Press(game.ToMove())
+		}
 	}
+	hint = nil
+	explanation = ""
+	selected = nil
 
-	return Empty
+	if game.Terminal() {
+		finishGame()
+	}
 }
 
-func resetGame() {
-	for i := 0; i < boardSize; i++ {
-		for j := 0; j < boardSize; j++ {
-			board[i][j] = Empty
+// undoMove отменяет последний ход, а в игре с компьютером — и его ответ,
+// чтобы снова ходил человек. Окончившуюся партию отменять нельзя: её результат уже записан.
+// В партии с часами и в задачах ходы не отменяются: в задаче отмена вернула бы
+// решающему уже засчитанный ход.
+func undoMove() {
+	if gameOver || clock != nil || gameMode == modePuzzle || len(history) == 0 {
+		return
+	}
+	cancelSearch()
+	for len(history) > 0 {
+		game = history[len(history)-1]
+		history = history[:len(history)-1]
+		played = played[:len(played)-1]
+		moveCount--
+		if game.ToMove() != aiSide {
+			break
 		}
 	}
+	currentTurn = game.ToMove()
+	searched = nil
+	hint = nil
+	explanation = ""
+	selected = nil
+}
 
-	currentTurn = Cross
-	winner = Empty
-	winnerString = ""
-	gameOver = false
-	highlighted = false
-	bestMoveRow = -1
-	bestMoveCol = -1
+// finishGame заполняет сообщение о результате и сохраняет партию в статистику.
+func finishGame() {
+	endGame(game.Winner(), game.Hints().Note)
+
+	// Агент Q-обучения учится на каждой партии с человеком и сохраняет опыт между сеансами.
+	if q, ok := ai.(*qEngine); ok && aiSide != Empty && gameMode != modePuzzle {
+		q.Table.learnGame(append(history, game), aiSide)
+		if qTableFile != "" {
+			if err := q.Table.save(qTableFile); err != nil {
+				log.Println(err)
+			}
+		}
+	}
 }
 
-func update(screen *ebiten.Image) error {
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && !gameOver {
-		x, y := ebiten.CursorPosition()
-		if x < 0 || y < 0 || x >= screenWidth || y >= screenHeight {
-			return nil
+// checkClock заканчивает партию, если у игрока кончилось время: он проигрывает.
+func checkClock() {
+	if clock == nil || gameOver {
+		return
+	}
+	if p := clock.Flagged(); p != Empty {
+		cancelSearch()
+		clock.Press(Empty)
+		endGame(other(p), "on time")
+	}
+}
+
+// endGame заканчивает партию победой w (Empty — ничья): заполняет сообщение
+// с пометкой note и сохраняет партию в статистику.
+func endGame(w Player, note string) {
+	winner = w
+	switch {
+	case winner == Empty:
+		winnerString = "It's a draw!"
+	case aiSide == Empty && winner == Circle:
+		winnerString = "Player 1 wins"
+	case aiSide == Empty:
+		winnerString = "Player 2 wins"
+	case winner == aiSide:
+		winnerString = "Computer wins"
+	default:
+		winnerString = "You win"
+	}
+	if note != "" {
+		winnerString += " (" + note + ")"
+	}
+	gameOver = true
+
+	// Задачи в статистику партий не записываются.
+	if gameMode == modePuzzle {
+		if winner != aiSide {
+			puzzles.solve()
+			winnerString = "Solved!"
+		} else {
+			puzzles.fail()
 		}
+		return
+	}
 
-		row, col := y/cellSize, x/cellSize
-		if board[row][col] == Empty {
-			board[row][col] = currentTurn
-			winner = checkWinner()
-			if winner != Empty || isBoardFull() {
-				if winner != Empty {
-					switch winner {
-					case Circle:
-						winnerString = "Player 1 wins"
-					case Cross:
-						winnerString = "Player 2 wins"
-					}
-				} else {
-					winnerString = "It's a draw!"
-				}
-				gameOver = true
-			} else {
-				if currentTurn == Circle {
-					currentTurn = Cross
-				} else {
-					currentTurn = Circle
-				}
-			}
+	if aiSide == Empty {
+		recordGame("human", Circle)
+	} else {
+		recordGame("ai", other(aiSide))
+	}
+}
+
+// drawClocks показывает время игроков в правом верхнем углу; идущие часы отмечаются звёздочкой.
+func drawClocks(screen *ebiten.Image) {
+	if clock == nil {
+		return
+	}
+	var parts []string
+	for _, p := range []Player{Cross, Circle} {
+		mark := " "
+		if clock.Running == p {
+			mark = "*"
 		}
+		parts = append(parts, mark+symbolName(p)+" "+formatClock(clock.Left(p)))
 	}
+	text := strings.Join(parts, " ")
+	ebitenutil.DebugPrintAt(screen, text, screenWidth-6*len(text)-5, 0)
+}
 
-	if !gameOver && currentTurn == Circle {
-		FindBestMove()
+// failPuzzle заканчивает задачу после неверного ответа и показывает выигрывающий ход.
+func failPuzzle() {
+	best, _, _ := bestMove(game, 2*puzzles.Left-1)
+	winnerString = "Wrong! " + formatMove(game, best) + " wins"
+	gameOver = true
+	if clock != nil {
+		clock.Press(Empty)
 	}
+	hint = nil
+	explanation = ""
+	selected = nil
+}
 
+// recordGame сохраняет результат завершённой партии в файл статистики.
+// Результат записывается с точки зрения игрока-человека, играющего фишками human.
+func recordGame(opponent string, human Player) {
+	if statsFile == "" {
+		return
+	}
+	rec := GameRecord{
+		Players:    [2]string{"human", opponent},
+		Opponent:   opponent,
+		Mode:       game.Name(),
+		Result:     resultFor(winner, human),
+		Moves:      moveCount,
+		Duration:   time.Since(gameStart),
+		FinishedAt: time.Now(),
+	}
+	if err := appendStats(statsFile, rec); err != nil {
+		log.Println(err)
+	}
+}
+
+// toggleStats открывает или закрывает экран статистики.
+func toggleStats() {
+	showStats = !showStats
+	if !showStats || statsFile == "" {
+		return
+	}
+	records, err := loadStats(statsFile)
+	if err != nil {
+		log.Println(err)
+	}
+	statsRecords = records
+}
+
+// drawStats рисует экран статистики поверх игрового поля.
+func drawStats(screen *ebiten.Image) {
+	ebitenutil.DrawRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{30, 30, 30, 255})
+	ebitenutil.DebugPrintAt(screen, "Statistics  (S-back)", 10, 10)
+	ebitenutil.DebugPrintAt(screen, formatStats(statsRecords), 10, 40)
+}
+
+// handleKeys обрабатывает общие для всех режимов клавиши.
+func handleKeys() {
 	if ebiten.IsKeyPressed(ebiten.KeyR) {
 		resetGame()
 	}
 
+	if inpututil.IsKeyJustPressed(ebiten.KeyU) {
+		undoMove()
+	}
+
 	if ebiten.IsKeyPressed(ebiten.KeyQ) {
+		cancelSearch()
 		os.Exit(0)
 	}
 
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		toggleStats()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		toggleReport()
+	}
+}
+
+// toggleReport открывает или закрывает разбор окончившейся партии.
+// Разбор запускается в фоне при первом открытии.
+func toggleReport() {
+	if !gameOver {
+		return
+	}
+	showReport = !showReport
+	if !showReport || report != nil || analysing != nil || len(history) == 0 {
+		return
+	}
+	analysing = startAnalysis(context.Background(), history[0], played)
+}
+
+// updateReport запоминает готовый разбор партии.
+func updateReport() {
+	if analysing == nil {
+		return
+	}
+	res, ok := analysing.poll()
+	if !ok {
+		return
+	}
+	analysing = nil
+	if res.Err != nil {
+		log.Println(res.Err)
+	}
+	report = res.Report
+}
+
+// drawReport выводит разбор партии поверх поля.
+func drawReport(screen *ebiten.Image) {
+	ebitenutil.DrawRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{30, 30, 30, 255})
+	ebitenutil.DebugPrintAt(screen, "Analysis  (A-back)", 10, 10)
+	if analysing != nil {
+		ebitenutil.DebugPrintAt(screen, "analysing...", 10, 40)
+		return
+	}
+	ebitenutil.DebugPrintAt(screen, formatAnalysis(report), 10, 40)
+}
+
+func update(screen *ebiten.Image) error {
+	h := game.Hints()
+	l := layoutFor(h)
+	hover, onBoard := l.cellAt(ebiten.CursorPosition())
+	humanTurn := !gameOver && !showStats && !showReport && game.ToMove() != aiSide
+
+	if h.Symbols && (inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) || inpututil.IsKeyJustPressed(ebiten.KeySpace)) {
+		chosenSymbol = other(chosenSymbol)
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && onBoard && humanTurn {
+		clickCell(hover)
+	}
+
+	updateSearch()
+	updateReport()
+	checkClock()
+	handleKeys()
+
 	if ebiten.IsDrawingSkipped() {
 		return nil
 	}
 
-	for i := 1; i < boardSize; i++ {
-		ebitenutil.DrawLine(screen, 0, float64(i*cellSize), screenWidth, float64(i*cellSize), color.Black)
-		ebitenutil.DrawLine(screen, float64(i*cellSize), 0, float64(i*cellSize), screenHeight, color.Black)
+	if showStats {
+		drawStats(screen)
+		return nil
+	}
+	if showReport {
+		drawReport(screen)
+		return nil
+	}
+
+	drawBoard(screen, layoutFor(game.Hints()), hover, onBoard)
+	if thinking() {
+		ebitenutil.DebugPrintAt(screen, "thinking...", 5, 0)
+	} else if lastSearch.Nodes > 0 {
+		ebitenutil.DebugPrintAt(screen, formatSearchInfo(lastSearch), 5, 0)
+	}
+	if explanation != "" && !gameOver {
+		ebitenutil.DebugPrintAt(screen, explanation, 5, 12)
+	}
+	if gameMode == modePuzzle {
+		ebitenutil.DebugPrintAt(screen, puzzleTitle+"; "+puzzles.Status(), 5, screenHeight-16)
 	}
+	drawClocks(screen)
+	drawGameOver(screen)
 
-	for i := 0; i < boardSize; i++ {
-		for j := 0; j < boardSize; j++ {
-			var symbol string
-			var textColor color.Color
-			switch board[i][j] {
-			case Circle:
-				symbol = "O"
-				textColor = color.RGBA{36, 36, 36, 255}
-			case Cross:
-				symbol = "X"
-				textColor = color.RGBA{65, 65, 65, 255}
-			default:
-				textColor = color.White
+	return nil
+}
+
+// movesAt возвращает допустимые ходы в клетку c, а в вариантах с гравитацией — в её столбец.
+func movesAt(r Rules, c Cell3) []Move {
+	drop := r.Hints().Drop
+	var moves []Move
+	for _, m := range r.LegalMoves() {
+		if m.Layer == c.Layer && m.Col == c.Col && (drop || m.Row == c.Row) {
+			moves = append(moves, m)
+		}
+	}
+	return moves
+}
+
+// clickCell обрабатывает щелчок по клетке: выбор своей фишки для переноса или ход.
+func clickCell(c Cell3) {
+	h := game.Hints()
+	if h.Shift && game.At(c) == game.ToMove() {
+		selected = &Cell{c.Row, c.Col}
+		return
+	}
+
+	for _, m := range movesAt(game, c) {
+		if m.Shift && (selected == nil || m.From != *selected) {
+			continue
+		}
+		if h.Symbols && m.Symbol != chosenSymbol {
+			continue
+		}
+		playMove(m)
+		return
+	}
+}
+
+// boardLayout задаёт размещение поля на экране: слои идут слева направо через пустой столбец.
+type boardLayout struct {
+	x0, y0, size int
+	hints        RenderHints
+}
+
+// layoutFor подбирает размер клетки так, чтобы поле поместилось в окно.
+// В вариантах с гравитацией над полем остаётся строка для превью падения фишки.
+func layoutFor(h RenderHints) boardLayout {
+	top := 0
+	if h.Drop {
+		top = 1
+	}
+	cols := h.Layers*h.Cols + h.Layers - 1
+	size := min(screenWidth/cols, screenHeight/(h.Rows+top))
+	return boardLayout{
+		x0:    (screenWidth - cols*size) / 2,
+		y0:    (screenHeight-(h.Rows+top)*size)/2 + top*size,
+		size:  size,
+		hints: h,
+	}
+}
+
+// origin возвращает левый верхний угол слоя на экране.
+func (l boardLayout) origin(layer int) (int, int) {
+	return l.x0 + layer*(l.hints.Cols+1)*l.size, l.y0
+}
+
+// cellAt переводит координаты курсора в клетку поля. Строка превью над полем
+// с гравитацией относится к верхней строке.
+func (l boardLayout) cellAt(x, y int) (Cell3, bool) {
+	for layer := 0; layer < l.hints.Layers; layer++ {
+		ox, oy := l.origin(layer)
+		if l.hints.Drop && y >= oy-l.size && y < oy {
+			y = oy
+		}
+		if x >= ox && y >= oy && x < ox+l.hints.Cols*l.size && y < oy+l.hints.Rows*l.size {
+			return Cell3{layer, (y - oy) / l.size, (x - ox) / l.size}, true
+		}
+	}
+	return Cell3{}, false
+}
+
+// drawBoard рисует позицию по подсказкам варианта: фишки, подсветку допустимых клеток,
+// подсказку лучшего хода, превью падения фишки и выигрышную линию.
+func drawBoard(screen *ebiten.Image, l boardLayout, hover Cell3, onBoard bool) {
+	h := l.hints
+	active := make(map[Cell3]bool)
+	for _, c := range h.Active {
+		active[c] = true
+	}
+	inLine := make(map[Cell3]bool)
+	for _, c := range h.Line {
+		inLine[c] = true
+	}
+
+	var preview *Move
+	if h.Drop && onBoard && !gameOver && game.ToMove() != aiSide {
+		if moves := movesAt(game, hover); len(moves) > 0 {
+			preview = &moves[0]
+		}
+	}
+
+	for layer := 0; layer < h.Layers; layer++ {
+		ox, oy := l.origin(layer)
+		for row := 0; row < h.Rows; row++ {
+			for col := 0; col < h.Cols; col++ {
+				c := Cell3{layer, row, col}
+				p := game.At(c)
+
+				var bg color.Color = color.White
+				switch {
+				case h.Layers > 1 && inLine[c]:
+					bg = hintColor
+				case p == Circle:
+					bg = circleColor
+				case p == Cross:
+					bg = crossColor
+				case preview != nil && c == preview.To():
+					bg = previewColor
+				case hint != nil && c == hint.To():
+					bg = hintColor
+				case h.Marked[c] != Empty:
+					bg = markedColors[h.Marked[c]]
+				case active[c]:
+					bg = activeColor
+				}
+				if selected != nil && row == selected.Row && col == selected.Col {
+					bg = selectedColor
+				}
+
+				x, y := ox+col*l.size, oy+row*l.size
+				ebitenutil.DrawRect(screen, float64(x+lineWidth), float64(y+lineWidth), float64(l.size-2*lineWidth), float64(l.size-2*lineWidth), bg)
+				if p != Empty {
+					ebitenutil.DebugPrintAt(screen, symbolName(p), x+l.size/2-3, y+l.size/2-8)
+				}
 			}
+		}
 
-			if i == bestMoveRow && j == bestMoveCol && currentTurn == Circle {
-				textColor = color.RGBA{255, 0, 0, 255}
+		// Толстые линии между малыми полями.
+		if h.Block > 0 {
+			for i := h.Block; i < h.Rows; i += h.Block {
+				for w := -1; w <= 1; w++ {
+					ebitenutil.DrawLine(screen, float64(ox), float64(oy+i*l.size+w), float64(ox+h.Cols*l.size), float64(oy+i*l.size+w), color.Black)
+					ebitenutil.DrawLine(screen, float64(ox+i*l.size+w), float64(oy), float64(ox+i*l.size+w), float64(oy+h.Rows*l.size), color.Black)
+				}
 			}
+		}
+	}
 
-			ebitenutil.DrawRect(screen, float64(j*cellSize)+lineWidth, float64(i*cellSize)+lineWidth, float64(cellSize)-2*lineWidth, float64(cellSize)-2*lineWidth, textColor)
+	if preview != nil {
+		ox, oy := l.origin(preview.Layer)
+		ebitenutil.DebugPrintAt(screen, symbolName(game.ToMove()), ox+preview.Col*l.size+l.size/2-3, oy-l.size/2-8)
+	}
 
-			if symbol != "" {
-				ebitenutil.DebugPrintAt(screen, symbol, j*cellSize+cellSize/2-5, i*cellSize+cellSize/2-10)
-			}
+	// Линию, лежащую в одном слое, зачёркиваем; линии сквозь слои подсвечены цветом клеток.
+	if len(h.Line) > 0 && h.Line[0].Layer == h.Line[len(h.Line)-1].Layer {
+		cells := make([]Cell, len(h.Line))
+		for i, c := range h.Line {
+			cells[i] = Cell{c.Row, c.Col}
 		}
+		ox, oy := l.origin(h.Line[0].Layer)
+		drawWinLine(screen, cells, ox, oy, l.size, winAnimProgress())
 	}
 
+	if h.Symbols && !gameOver {
+		ebitenutil.DebugPrintAt(screen, "Your symbol: "+symbolName(chosenSymbol)+" (right click / space to switch)", 5, screenHeight-16)
+	}
+}
+
+// drawGameOver выводит полосу с результатом партии, если она окончена.
+func drawGameOver(screen *ebiten.Image) {
 	if gameOver {
 		bgColor := color.RGBA{255, 0, 0, 255}
 		ebitenutil.DrawRect(screen, 0, 0, screenWidth, 20, bgColor)
-		ebitenutil.DebugPrintAt(screen, winnerString+"  (R-reset; Q-exit)", 75, 0)
+		ebitenutil.DebugPrintAt(screen, winnerString+"  (R-reset; S-stats; A-analysis; Q-exit)", 20, 0)
 	}
+}
 
-	return nil
+// winAnimProgress продвигает анимацию выигрышной линии на один кадр
+// и возвращает долю уже нарисованной линии от 0 до 1.
+func winAnimProgress() float64 {
+	if winAnimTick < winAnimTicks {
+		winAnimTick++
+	}
+	return float64(winAnimTick) / winAnimTicks
+}
+
+// drawWinLine зачёркивает выигрышную линию на поле с левым верхним углом (x0, y0)
+// и клетками размера size. progress от 0 до 1 задаёт долю уже нарисованной линии.
+func drawWinLine(screen *ebiten.Image, cells []Cell, x0, y0, size int, progress float64) {
+	first, last := cells[0], cells[len(cells)-1]
+	x1 := float64(x0 + first.Col*size + size/2)
+	y1 := float64(y0 + first.Row*size + size/2)
+	x2 := float64(x0 + last.Col*size + size/2)
+	y2 := float64(y0 + last.Row*size + size/2)
+
+	// Немного выходим за центры крайних клеток, чтобы линия перечёркивала фишки целиком.
+	dx, dy := x2-x1, y2-y1
+	length := math.Hypot(dx, dy)
+	ext := float64(size) / 3
+	x1, y1 = x1-dx/length*ext, y1-dy/length*ext
+	x2, y2 = x2+dx/length*ext, y2+dy/length*ext
+
+	x2 = x1 + (x2-x1)*progress
+	y2 = y1 + (y2-y1)*progress
+
+	lineColor := color.RGBA{255, 0, 0, 255}
+	for off := -2.0; off <= 2; off++ {
+		ebitenutil.DrawLine(screen, x1+off, y1, x2+off, y2, lineColor)
+		ebitenutil.DrawLine(screen, x1, y1+off, x2, y2+off, lineColor)
+	}
 }
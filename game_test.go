@@ -1,45 +1,26 @@
 package main
 
 import (
+	"reflect"
 	"testing"
 )
 
-func TestFindBestMove(t *testing.T) {
-	// Создаем тестовое игровое поле
-
-	// Заполняем поле, чтобы убедиться, что bestMoveRow и bestMoveCol изначально равны -1
-	bestMoveRow = -1
-	bestMoveCol = -1
-
-	// Вызываем функцию FindBestMove
-	FindBestMove()
-
-	// Проверяем, что bestMoveRow и bestMoveCol теперь имеют правильные значения
-	expectedRow := 0
-	expectedCol := 0
-	if bestMoveRow != expectedRow || bestMoveCol != expectedCol {
-		t.Errorf("Ожидалось (%d, %d), но получено (%d, %d)", expectedRow, expectedCol, bestMoveRow, bestMoveCol)
+func TestBestMoveClassic(t *testing.T) {
+	// На пустом поле все ходы ничейные, и выбирается первый.
+	r := bitRulesFromClassic(classicFromText(3, Circle, "...", "...", "..."))
+	m, _, ok := bestMove(r, boardSize*boardSize)
+	if !ok || m.Row != 0 || m.Col != 0 {
+		t.Errorf("Ожидалось (0, 0), но получено (%d, %d)", m.Row, m.Col)
 	}
 
-	// Сбрасываем значения bestMoveRow и bestMoveCol
-	bestMoveRow = -1
-	bestMoveCol = -1
-
-	// Создаем другое тестовое игровое поле
-	board = [3][3]Player{
-		{Circle, Empty, Cross},
-		{Cross, Circle, Cross},
-		{Empty, Circle, Empty},
+	// Нолики выигрывают сразу ходом (0, 1).
+	r = bitRulesFromClassic(classicFromText(3, Circle, "O.X", "XOX", ".O."))
+	m, _, ok = bestMove(r, boardSize*boardSize)
+	if !ok || m.Row != 0 || m.Col != 1 {
+		t.Errorf("Ожидалось (0, 1), но получено (%d, %d)", m.Row, m.Col)
 	}
-
-	// Вызываем функцию FindBestMove
-	FindBestMove()
-
-	// Проверяем, что bestMoveRow и bestMoveCol теперь имеют правильные значения
-	expectedRow = 0
-	expectedCol = 1
-	if bestMoveRow != expectedRow || bestMoveCol != expectedCol {
-		t.Errorf("Ожидалось (%d, %d), но получено (%d, %d)", expectedRow, expectedCol, bestMoveRow, bestMoveCol)
+	if reason := explainMove(r, m, explainDepth(r)); reason != reasonWin {
+		t.Errorf("Ожидалось объяснение %q, но получено %q", reasonWin, reason)
 	}
 }
 
@@ -159,14 +140,14 @@ func TestResetGame(t *testing.T) {
 	currentTurn = Cross
 	winner = Circle
 	gameOver = true
-	bestMoveRow = 1
-	bestMoveCol = 2
+	hint = &Move{Row: 1, Col: 2}
+	explanation = "hint: 1,2"
 
 	// Вызываем функцию resetGame
 	resetGame()
 
 	// Проверяем, что все переменные сброшены к начальным значениям
-	if currentTurn != Cross || winner != Empty || gameOver || bestMoveRow != -1 || bestMoveCol != -1 {
+	if currentTurn != Cross || winner != Empty || gameOver || hint != nil || explanation != "" {
 		t.Errorf("Переменные не были сброшены к начальным значениям")
 	}
 }
@@ -189,3 +170,86 @@ func TestMin(t *testing.T) {
 		t.Errorf("Ожидалось %d, но получено %d", expected, result)
 	}
 }
+
+func TestFindLine(t *testing.T) {
+	// Поле 3×3: выигрышная диагональ крестиков.
+	board := [3][3]Player{
+		{Cross, Circle, Circle},
+		{Empty, Cross, Empty},
+		{Circle, Empty, Cross},
+	}
+	at := func(row, col int) Player { return board[row][col] }
+	w, cells := findLine(at, 3, 3, 3)
+	expected := []Cell{{0, 0}, {1, 1}, {2, 2}}
+	if w != Cross || !reflect.DeepEqual(cells, expected) {
+		t.Errorf("Ожидалось %v %v, но получено %v %v", Cross, expected, w, cells)
+	}
+
+	// Поле без линии.
+	board[1][1] = Empty
+	if w, cells := findLine(at, 3, 3, 3); w != Empty || cells != nil {
+		t.Errorf("Ожидалось отсутствие линии, но получено %v %v", w, cells)
+	}
+
+	// Прямоугольное поле 4×5, четыре в ряд по побочной диагонали.
+	big := [4][5]Player{}
+	for i := 0; i < 4; i++ {
+		big[i][4-i] = Circle
+	}
+	w, cells = findLine(func(row, col int) Player { return big[row][col] }, 4, 5, 4)
+	expected = []Cell{{0, 4}, {1, 3}, {2, 2}, {3, 1}}
+	if w != Circle || !reflect.DeepEqual(cells, expected) {
+		t.Errorf("Ожидалось %v %v, но получено %v %v", Circle, expected, w, cells)
+	}
+
+	// Три в ряд недостаточно при k = 4.
+	big[0][4] = Empty
+	if w, _ := findLine(func(row, col int) Player { return big[row][col] }, 4, 5, 4); w != Empty {
+		t.Errorf("Ожидалось отсутствие линии, но получено %v", w)
+	}
+}
+
+func TestClassicWinLine(t *testing.T) {
+	r := classicFromText(3, Cross, "OX.", "OX.", "O.X")
+	expected := []Cell3{{0, 0, 0}, {0, 1, 0}, {0, 2, 0}}
+	if w, line := r.Winner(), r.Hints().Line; w != Circle || !reflect.DeepEqual(line, expected) {
+		t.Errorf("Ожидалось %v %v, но получено %v %v", Circle, expected, w, line)
+	}
+}
+
+func TestMisere(t *testing.T) {
+	// Собравший линию проигрывает.
+	lineBoard := [3][3]Player{
+		{Cross, Cross, Cross},
+		{Circle, Circle, Empty},
+		{Empty, Empty, Empty},
+	}
+	misere = true
+	defer func() { misere = false }()
+	if result := evaluate(lineBoard); result != 1 {
+		t.Errorf("Ожидалось 1, но получено %d", result)
+	}
+
+	// Мизер 3×3 при правильной игре — ничья, и единственный непроигрывающий первый ход — центр.
+	start := newClassicRules(boardSize, winLength, true)
+	start.Turn = Circle
+	for _, m := range start.LegalMoves() {
+		result := -minimax(start.Apply(m), boardSize*boardSize-1, -inf, inf)
+		if m.Row == 1 && m.Col == 1 && result != 0 {
+			t.Errorf("Первый ход в центр: ожидалась ничья, но получено %d", result)
+		}
+		if (m.Row != 1 || m.Col != 1) && result >= 0 {
+			t.Errorf("Первый ход (%d, %d): ожидался проигрыш, но получено %d", m.Row, m.Col, result)
+		}
+	}
+	if m, _, _ := bestMove(bitRulesFromClassic(start), boardSize*boardSize); m.Row != 1 || m.Col != 1 {
+		t.Errorf("Ожидалось (1, 1), но получено (%d, %d)", m.Row, m.Col)
+	}
+
+	// Нолики не должны собирать собственную линию в (2, 1), хотя в обычной игре это победа.
+	r := classicFromText(3, Circle, "XOX", "XOO", "...")
+	r.Misere = true
+	if m, _, _ := bestMove(bitRulesFromClassic(r), boardSize*boardSize); m.Row == 2 && m.Col == 1 {
+		t.Errorf("ИИ собрал линию и проиграл в мизере")
+	}
+}
@@ -2,9 +2,10 @@ module lab3sem2
 
 go 1.21.1
 
+require github.com/hajimehoshi/ebiten v1.12.12
+
 require (
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20231124074035-2de0cf0c80af // indirect
-	github.com/hajimehoshi/ebiten v1.12.12 // indirect
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646 // indirect
 	golang.org/x/exp v0.0.0-20190731235908-ec7cb31e5a56 // indirect
 	golang.org/x/exp/shiny v0.0.0-20231206192017-f3f8817b8deb // indirect
@@ -0,0 +1,159 @@
+package main
+
+const (
+	gravityRows  = 6
+	gravityCols  = 7
+	gravityK     = 4
+	gravityDepth = 6
+)
+
+// GravityBoard хранит состояние режима с гравитацией: фишка падает на дно выбранного столбца.
+type GravityBoard struct {
+	Cells [gravityRows][gravityCols]Player
+	Turn  Player
+}
+
+// gravityLines содержит все отрезки длины gravityK на поле.
+var gravityLines = gridLines(gravityRows, gravityCols, gravityK)
+
+// gravityOrder задаёт порядок перебора столбцов: от центра к краям, что ускоряет отсечение.
+var gravityOrder = [gravityCols]int{3, 2, 4, 1, 5, 0, 6}
+
+// newGravityBoard создаёт пустую партию, в которой первыми ходят крестики.
+func newGravityBoard() GravityBoard {
+	return GravityBoard{Turn: Cross}
+}
+
+// gridLines перечисляет все отрезки из k клеток подряд на поле rows×cols.
+func gridLines(rows, cols, k int) [][]Cell {
+	var lines [][]Cell
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			for _, d := range lineDirections {
+				endR, endC := r+d[0]*(k-1), c+d[1]*(k-1)
+				if endR < 0 || endR >= rows || endC < 0 || endC >= cols {
+					continue
+				}
+				line := make([]Cell, k)
+				for n := 0; n < k; n++ {
+					line[n] = Cell{r + d[0]*n, c + d[1]*n}
+				}
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// dropRow возвращает строку, на которую упадёт фишка в столбце col, или -1, если столбец заполнен.
+func (g *GravityBoard) dropRow(col int) int {
+	for r := gravityRows - 1; r >= 0; r-- {
+		if g.Cells[r][col] == Empty {
+			return r
+		}
+	}
+	return -1
+}
+
+// line возвращает победителя и его линию или Empty, если линии нет.
+func (g *GravityBoard) line() (Player, []Cell) {
+	return findLine(func(row, col int) Player { return g.Cells[row][col] }, gravityRows, gravityCols, gravityK)
+}
+
+// legalMoves возвращает незаполненные столбцы в порядке перебора gravityOrder.
+// В отличие от классики, ход задаётся столбцом, а не любой пустой клеткой.
+func (g *GravityBoard) legalMoves() []int {
+	var moves []int
+	if w, _ := g.line(); w != Empty {
+		return moves
+	}
+	for _, col := range gravityOrder {
+		if g.Cells[0][col] == Empty {
+			moves = append(moves, col)
+		}
+	}
+	return moves
+}
+
+// play возвращает позицию после хода в столбец col.
+func (g GravityBoard) play(col int) GravityBoard {
+	g.Cells[g.dropRow(col)][col] = g.Turn
+	g.Turn = other(g.Turn)
+	return g
+}
+
+// gravityLineWeights задаёт ценность открытого отрезка по числу фишек в нём.
+var gravityLineWeights = [gravityK]int{0, 1, 8, 50}
+
+// gravityHeuristic оценивает позицию с точки зрения ноликов по отрезкам,
+// занятым фишками только одного игрока.
+func gravityHeuristic(g *GravityBoard) int {
+	score := 0
+	for _, line := range gravityLines {
+		circles, crosses := 0, 0
+		for _, c := range line {
+			switch g.Cells[c.Row][c.Col] {
+			case Circle:
+				circles++
+			case Cross:
+				crosses++
+			}
+		}
+		if crosses == 0 {
+			score += gravityLineWeights[circles]
+		} else if circles == 0 {
+			score -= gravityLineWeights[crosses]
+		}
+	}
+	return score
+}
+
+func (g *GravityBoard) Name() string {
+	return modeGravity
+}
+
+func (g *GravityBoard) ToMove() Player {
+	return g.Turn
+}
+
+func (g *GravityBoard) At(c Cell3) Player {
+	return g.Cells[c.Row][c.Col]
+}
+
+func (g *GravityBoard) Winner() Player {
+	w, _ := g.line()
+	return w
+}
+
+func (g *GravityBoard) LegalMoves() []Move {
+	var moves []Move
+	for _, col := range g.legalMoves() {
+		moves = append(moves, Move{Row: g.dropRow(col), Col: col, Symbol: g.Turn})
+	}
+	return moves
+}
+
+func (g *GravityBoard) Apply(m Move) Rules {
+	next := g.play(m.Col)
+	return &next
+}
+
+func (g *GravityBoard) Terminal() bool {
+	return len(g.legalMoves()) == 0
+}
+
+func (g *GravityBoard) Evaluate() int {
+	if g.Turn == Cross {
+		return -gravityHeuristic(g)
+	}
+	return gravityHeuristic(g)
+}
+
+func (g *GravityBoard) Hints() RenderHints {
+	h := RenderHints{Layers: 1, Rows: gravityRows, Cols: gravityCols, Drop: true}
+	_, line := g.line()
+	for _, c := range line {
+		h.Line = append(h.Line, Cell3{0, c.Row, c.Col})
+	}
+	return h
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestGravityDrop(t *testing.T) {
+	g := newGravityBoard()
+	g = g.play(3)
+	g = g.play(3)
+	if g.Cells[gravityRows-1][3] != Cross || g.Cells[gravityRows-2][3] != Circle {
+		t.Errorf("Фишки должны падать на дно столбца друг на друга")
+	}
+
+	// Заполненный столбец исключается из допустимых ходов.
+	for g.dropRow(0) != -1 {
+		g = g.play(0)
+	}
+	for _, col := range g.legalMoves() {
+		if col == 0 {
+			t.Errorf("Заполненный столбец 0 не должен быть допустимым ходом")
+		}
+	}
+	if len(g.legalMoves()) != gravityCols-1 {
+		t.Errorf("Ожидалось %d ходов, но получено %d", gravityCols-1, len(g.legalMoves()))
+	}
+}
+
+func TestGridLines(t *testing.T) {
+	// На поле 6×7 есть 69 отрезков из четырёх клеток.
+	if len(gravityLines) != 69 {
+		t.Errorf("Ожидалось 69 отрезков, но получено %d", len(gravityLines))
+	}
+}
+
+func TestGravityBestMove(t *testing.T) {
+	// Нолики выигрывают, поставив четвёртую фишку в столбец 4.
+	g := newGravityBoard()
+	for _, col := range []int{0, 1, 0, 2, 6, 3} {
+		g = g.play(col)
+	}
+	g.Turn = Circle
+	m, _, ok := bestMove(&g, gravityDepth)
+	if !ok || m.Col != 4 {
+		t.Errorf("Ожидался ход в столбец 4, но получен %d", m.Col)
+	}
+
+	// Крестики должны занять столбец 4 раньше ноликов.
+	g.Turn = Cross
+	m, _, ok = bestMove(&g, gravityDepth)
+	if !ok || m.Col != 4 {
+		t.Errorf("Ожидался ход в столбец 4, но получен %d", m.Col)
+	}
+
+	g = g.play(4)
+	g = g.play(4)
+	if w, line := g.line(); w != Empty {
+		t.Errorf("Победителя быть не должно, но получено %v %v", w, line)
+	}
+}
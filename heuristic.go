@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// HeuristicWeights задаёт веса эвристической оценки незавершённой позиции
+// классических крестиков-ноликов. Отрезок — любые k клеток подряд по строке,
+// столбцу или диагонали; открытым он считается, если в нём фишки только одного игрока.
+type HeuristicWeights struct {
+	Open   int `json:"open"`   // Открытый отрезок с одной фишкой.
+	Two    int `json:"two"`    // Открытый отрезок с двумя фишками.
+	Three  int `json:"three"`  // Открытый отрезок с тремя и более фишками.
+	Center int `json:"center"` // Фишка в центре поля (на чётном поле — в одной из четырёх центральных клеток).
+	Corner int `json:"corner"` // Фишка в углу поля.
+	Fork   int `json:"fork"`   // Вилка: две и более клетки, каждая из которых сразу завершает линию.
+}
+
+// defaultWeights — веса по умолчанию. Вилка почти всегда выигрывает,
+// поэтому стоит больше любого набора отрезков, но меньше выигрыша.
+var defaultWeights = HeuristicWeights{
+	Open:   1,
+	Two:    10,
+	Three:  100,
+	Center: 3,
+	Corner: 2,
+	Fork:   1000,
+}
+
+// heuristicWeights — веса, используемые новыми партиями; задаются флагом -weights.
+var heuristicWeights = defaultWeights
+
+// loadWeights читает веса из JSON-файла. Не указанные в файле веса берутся по умолчанию.
+func loadWeights(path string) (HeuristicWeights, error) {
+	w := defaultWeights
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return w, err
+	}
+	if err := json.Unmarshal(data, &w); err != nil {
+		return w, fmt.Errorf("чтение весов %s: %w", path, err)
+	}
+	return w, nil
+}
+
+// heuristic оценивает позицию с точки зрения игрока p как разность его
+// и соперника показателей: открытых отрезков, центра, углов и вилок.
+func (r *ClassicRules) heuristic(p Player) int {
+	return r.sideScore(p) - r.sideScore(other(p))
+}
+
+// sideScore считает показатели одного игрока.
+func (r *ClassicRules) sideScore(p Player) int {
+	w := r.Weights
+	score := 0
+	// Клетки, сразу завершающие линию: достаточно знать первую и есть ли другая.
+	first, fork := noCell, false
+	for _, line := range r.Lines {
+		own, empty := 0, noCell
+		open := true
+		for _, c := range line {
+			switch r.Cells[c.Row*r.Size+c.Col] {
+			case p:
+				own++
+			case Empty:
+				empty = c
+			default:
+				open = false
+			}
+		}
+		if !open {
+			continue
+		}
+		switch {
+		case own == 1:
+			score += w.Open
+		case own == 2:
+			score += w.Two
+		case own >= 3:
+			score += w.Three
+		}
+		if own == r.K-1 {
+			if first == noCell {
+				first = empty
+			} else if empty != first {
+				fork = true
+			}
+		}
+	}
+	if fork {
+		score += w.Fork
+	}
+
+	// Центр — одна клетка на нечётном поле и четыре на чётном.
+	last, lo, hi := r.Size-1, (r.Size-1)/2, r.Size/2
+	for i, cell := range r.Cells {
+		if cell != p {
+			continue
+		}
+		row, col := i/r.Size, i%r.Size
+		if (row == 0 || row == last) && (col == 0 || col == last) {
+			score += w.Corner
+		}
+		if row >= lo && row <= hi && col >= lo && col <= hi {
+			score += w.Center
+		}
+	}
+	return score
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHeuristicSymmetry(t *testing.T) {
+	if v := newClassicRules(boardSize, winLength, false).Evaluate(); v != 0 {
+		t.Errorf("Пустое поле должно оцениваться в 0, но получено %d", v)
+	}
+	r := classicFromText(3, Circle, "....", ".X..", "..O.", "....")
+	if a, b := r.heuristic(Cross), r.heuristic(Circle); a != -b {
+		t.Errorf("Оценки сторон должны быть противоположны: %d и %d", a, b)
+	}
+}
+
+func TestHeuristicPlacement(t *testing.T) {
+	center := classicFromText(winLength, Circle, "...", ".X.", "...").heuristic(Cross)
+	corner := classicFromText(winLength, Circle, "X..", "...", "...").heuristic(Cross)
+	edge := classicFromText(winLength, Circle, ".X.", "...", "...").heuristic(Cross)
+	if !(center > corner && corner > edge) {
+		t.Errorf("Ожидалось центр > угол > край, но получено %d, %d, %d", center, corner, edge)
+	}
+
+	// На чётном поле центральных клеток четыре, и они равноценны.
+	a := classicFromText(4, Circle, "....", ".X..", "....", "....").heuristic(Cross)
+	b := classicFromText(4, Circle, "....", "....", ".X..", "....").heuristic(Cross)
+	if a != b {
+		t.Errorf("Центральные клетки поля 4×4 оценены по-разному: %d и %d", a, b)
+	}
+}
+
+func TestHeuristicFork(t *testing.T) {
+	// Крестики грозят собрать и верхнюю строку в (0, 1), и правый столбец в (1, 2).
+	fork := classicFromText(winLength, Circle, "X.X", ".O.", "O.X")
+	if s := fork.sideScore(Cross); s < defaultWeights.Fork {
+		t.Errorf("Вилка не учтена: %d", s)
+	}
+	// Одна угроза — ещё не вилка.
+	single := classicFromText(winLength, Circle, "X.X", ".O.", "...")
+	if s := single.sideScore(Cross); s >= defaultWeights.Fork {
+		t.Errorf("Одиночная угроза засчитана как вилка: %d", s)
+	}
+}
+
+func TestHeuristicMisere(t *testing.T) {
+	r := classicFromText(winLength, Circle, "...", ".X.", "...")
+	normal := r.Evaluate()
+	r.Misere = true
+	if m := r.Evaluate(); m != -normal || normal >= 0 {
+		t.Errorf("В мизере оценка должна менять знак: %d и %d", normal, m)
+	}
+}
+
+func TestHeuristicGuidesShallowSearch(t *testing.T) {
+	// Без оценки перебор на один полуход выбрал бы первую клетку, а с ней — центр.
+	m, _, ok := bestMove(newClassicRules(5, 4, false), 1)
+	if !ok || m.Row != 2 || m.Col != 2 {
+		t.Errorf("Ожидался ход в центр (2, 2), но получен %v", m)
+	}
+}
+
+func TestLoadWeights(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "weights.json")
+	if err := os.WriteFile(path, []byte(`{"fork": 7, "center": 0}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := loadWeights(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := defaultWeights
+	expected.Fork, expected.Center = 7, 0
+	if w != expected {
+		t.Errorf("Ожидалось %+v, но получено %+v", expected, w)
+	}
+
+	if _, err := loadWeights(filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("Ожидалась ошибка для отсутствующего файла")
+	}
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadWeights(path); err == nil {
+		t.Errorf("Ожидалась ошибка для испорченного файла")
+	}
+}
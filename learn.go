@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Параметры Q-обучения.
+const (
+	qAlpha   = 0.3  // Скорость обучения.
+	qGamma   = 0.95 // Дисконт: быстрый выигрыш ценнее долгого.
+	qEpsilon = 0.1  // Доля случайных ходов при обучении.
+)
+
+const qTableFileName = "qtable.json"
+
+// qCells — число клеток поля 3×3, на котором учится агент.
+const qCells = boardSize * boardSize
+
+// qSymmetries — перестановки клеток поля 3×3 при поворотах и отражениях:
+// клетка i переходит в клетку qSymmetries[t][i].
+var qSymmetries = func() [8][qCells]int {
+	var syms [8][qCells]int
+	for t := 0; t < 8; t++ {
+		for i := 0; i < qCells; i++ {
+			row, col := i/boardSize, i%boardSize
+			if t >= 4 {
+				col = boardSize - 1 - col
+			}
+			for k := 0; k < t%4; k++ {
+				row, col = col, boardSize-1-row
+			}
+			syms[t][i] = row*boardSize + col
+		}
+	}
+	return syms
+}()
+
+// QTable — таблица оценок ходов агента Q-обучения. Позиции хранятся в каноническом
+// виде: из восьми симметричных вариантов берётся вариант с наименьшим ключом,
+// поэтому опыт одной позиции переносится на все симметричные ей.
+// Оценка хода — ожидаемый результат для ходящего: 1 — выигрыш, -1 — проигрыш.
+type QTable struct {
+	Episodes int                     `json:"episodes"` // Сыгранные при обучении партии.
+	Q        map[int][qCells]float64 `json:"q"`
+
+	mu sync.RWMutex
+}
+
+// newQTable создаёт пустую таблицу: агент ещё ничего не знает.
+func newQTable() *QTable {
+	return &QTable{Q: make(map[int][qCells]float64)}
+}
+
+// defaultQTablePath возвращает путь к таблице в каталоге настроек пользователя.
+func defaultQTablePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, statsDirName, qTableFileName), nil
+}
+
+// loadQTable читает таблицу из файла. Отсутствующий файл означает необученного агента.
+func loadQTable(path string) (*QTable, error) {
+	q := newQTable()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, q); err != nil {
+		return nil, fmt.Errorf("чтение таблицы %s: %w", path, err)
+	}
+	if q.Q == nil {
+		q.Q = make(map[int][qCells]float64)
+	}
+	return q, nil
+}
+
+// save записывает таблицу в файл так же, как статистику: через временный файл.
+func (q *QTable) save(path string) error {
+	q.mu.RLock()
+	data, err := json.Marshal(q)
+	q.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// qCanonical возвращает ключ канонического вида позиции r и номер симметрии, приводящей к нему.
+func qCanonical(r Rules) (int, int) {
+	var cells [qCells]Player
+	for i := range cells {
+		cells[i] = r.At(Cell3{0, i / boardSize, i % boardSize})
+	}
+	bestKey, bestSym := -1, 0
+	for t, perm := range qSymmetries {
+		var moved [qCells]Player
+		for i, p := range cells {
+			moved[perm[i]] = p
+		}
+		key := 0
+		for _, p := range moved {
+			key = key*3 + int(p)
+		}
+		key = key*3 + int(r.ToMove())
+		if bestKey < 0 || key < bestKey {
+			bestKey, bestSym = key, t
+		}
+	}
+	return bestKey, bestSym
+}
+
+// qAction возвращает номер клетки хода m в канонической позиции с симметрией sym.
+func qAction(m Move, sym int) int {
+	return qSymmetries[sym][m.Row*boardSize+m.Col]
+}
+
+// value возвращает оценку хода m в позиции r.
+func (q *QTable) value(r Rules, m Move) float64 {
+	key, sym := qCanonical(r)
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.Q[key][qAction(m, sym)]
+}
+
+// bestValue возвращает наибольшую оценку хода в позиции r и сам ход.
+// При равных оценках выбирается первый ход из LegalMoves.
+func (q *QTable) bestValue(r Rules) (Move, float64, bool) {
+	moves := r.LegalMoves()
+	if len(moves) == 0 {
+		return Move{}, 0, false
+	}
+	key, sym := qCanonical(r)
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	row := q.Q[key]
+	best, bestVal := moves[0], row[qAction(moves[0], sym)]
+	for _, m := range moves[1:] {
+		if v := row[qAction(m, sym)]; v > bestVal {
+			best, bestVal = m, v
+		}
+	}
+	return best, bestVal, true
+}
+
+// qReward возвращает результат окончившейся партии для игрока side.
+func qReward(r Rules, side Player) float64 {
+	switch r.Winner() {
+	case Empty:
+		return 0
+	case side:
+		return 1
+	}
+	return -1
+}
+
+// update обновляет оценку хода m игрока из позиции s. next — позиция, в которой
+// этот игрок снова ходит, или окончившаяся партия.
+func (q *QTable) update(s Rules, m Move, next Rules) {
+	side := s.ToMove()
+	var target float64
+	if next.Terminal() {
+		target = qReward(next, side)
+	} else {
+		_, v, _ := q.bestValue(next)
+		target = qGamma * v
+	}
+
+	key, sym := qCanonical(s)
+	a := qAction(m, sym)
+	q.mu.Lock()
+	row := q.Q[key]
+	row[a] += qAlpha * (target - row[a])
+	q.Q[key] = row
+	q.mu.Unlock()
+}
+
+// choose выбирает ход при обучении: с вероятностью qEpsilon случайный, иначе лучший.
+func (q *QTable) choose(r Rules, rnd *rand.Rand) Move {
+	if rnd.Float64() < qEpsilon {
+		moves := r.LegalMoves()
+		return moves[rnd.Intn(len(moves))]
+	}
+	m, _, _ := q.bestValue(r)
+	return m
+}
+
+// train играет episodes обучающих партий. Если opponent равен nil, агент играет сам
+// с собой и учится за обе стороны, иначе учится за свою сторону, меняя цвет каждую партию.
+func (q *QTable) train(episodes int, opponent Engine, rnd *rand.Rand) {
+	type pendingMove struct {
+		s Rules
+		m Move
+	}
+	for ep := 0; ep < episodes; ep++ {
+		learner := Cross
+		if ep%2 == 1 {
+			learner = Circle
+		}
+		pending := make(map[Player]pendingMove)
+		r := Rules(newBitRules(boardSize, winLength, false))
+		for !r.Terminal() {
+			side := r.ToMove()
+			if p, ok := pending[side]; ok {
+				q.update(p.s, p.m, r)
+				delete(pending, side)
+			}
+			var m Move
+			if opponent != nil && side != learner {
+				m, _, _ = opponent.BestMove(context.Background(), r)
+			} else {
+				m = q.choose(r, rnd)
+				pending[side] = pendingMove{r, m}
+			}
+			r = r.Apply(m)
+		}
+		for _, p := range pending {
+			q.update(p.s, p.m, r)
+		}
+		q.mu.Lock()
+		q.Episodes++
+		q.mu.Unlock()
+	}
+}
+
+// learnGame учится на сыгранной партии: positions — позиции перед каждым ходом
+// и итоговая позиция, side — сторона агента. Ходы разбираются с конца,
+// чтобы результат партии сразу доходил до первых ходов.
+func (q *QTable) learnGame(positions []Rules, side Player) {
+	for i := len(positions) - 2; i >= 0; i-- {
+		s := positions[i]
+		if s.ToMove() != side {
+			continue
+		}
+		m, ok := moveBetween(s, positions[i+1])
+		if !ok {
+			return
+		}
+		next := positions[i+1]
+		if !next.Terminal() && i+2 < len(positions) {
+			next = positions[i+2]
+		}
+		q.update(s, m, next)
+	}
+	q.mu.Lock()
+	q.Episodes++
+	q.mu.Unlock()
+}
+
+// moveBetween находит ход, переводящий позицию from в позицию to.
+func moveBetween(from, to Rules) (Move, bool) {
+	for _, m := range from.LegalMoves() {
+		if to.At(m.To()) == from.ToMove() && from.At(m.To()) == Empty {
+			return m, true
+		}
+	}
+	return Move{}, false
+}
+
+// qEngine играет по таблице Q-обучения, всегда выбирая ход с наибольшей оценкой.
+// Годится только для классического поля 3×3.
+type qEngine struct {
+	Table *QTable
+}
+
+func (e *qEngine) Name() string {
+	return "q"
+}
+
+func (e *qEngine) BestMove(ctx context.Context, r Rules) (Move, SearchInfo, bool) {
+	start := time.Now()
+	m, _, ok := e.Table.bestValue(r)
+	return m, SearchInfo{Depth: 1, Nodes: len(r.LegalMoves()), Elapsed: time.Since(start)}, ok
+}
+
+// isQBoard сообщает, подходит ли вариант для агента Q-обучения.
+func isQBoard(r Rules) bool {
+	h := r.Hints()
+	return r.Name() == modeClassic && h.Layers == 1 && h.Rows == boardSize && h.Cols == boardSize
+}
+
+// qTableFile хранит путь к таблице агента; пустая строка отключает загрузку и сохранение.
+var (
+	qTableFile string
+	qTable     *QTable
+)
+
+// sharedQTable возвращает таблицу агента, загружая её из qTableFile при первом обращении.
+func sharedQTable() (*QTable, error) {
+	if qTable != nil {
+		return qTable, nil
+	}
+	if qTableFile == "" {
+		qTable = newQTable()
+		return qTable, nil
+	}
+	t, err := loadQTable(qTableFile)
+	if err != nil {
+		return nil, err
+	}
+	qTable = t
+	return qTable, nil
+}
+
+// runTrain реализует подкоманду "train": обучает агента и сохраняет таблицу.
+// opponent, равный nil, означает игру агента с самим собой.
+func runTrain(w io.Writer, episodes int, opponent Engine, rnd *rand.Rand) error {
+	t, err := sharedQTable()
+	if err != nil {
+		return err
+	}
+	t.train(episodes, opponent, rnd)
+	if qTableFile != "" {
+		if err := t.save(qTableFile); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "trained %d games, %d in total, %d positions known\n", episodes, t.Episodes, len(t.Q))
+	return err
+}
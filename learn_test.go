@@ -0,0 +1,105 @@
+package main
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func TestQSymmetries(t *testing.T) {
+	seen := make(map[[qCells]int]bool)
+	for _, perm := range qSymmetries {
+		var used [qCells]bool
+		for _, j := range perm {
+			used[j] = true
+		}
+		for i, ok := range used {
+			if !ok {
+				t.Fatalf("Симметрия %v не перестановка: клетка %d не занята", perm, i)
+			}
+		}
+		seen[perm] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("Ожидалось 8 различных симметрий, но получено %d", len(seen))
+	}
+
+	// Крестик в любом углу даёт одну и ту же каноническую позицию.
+	var keys []int
+	for _, c := range []Move{{Row: 0, Col: 0}, {Row: 0, Col: 2}, {Row: 2, Col: 0}, {Row: 2, Col: 2}} {
+		key, _ := qCanonical(newBitRules(boardSize, winLength, false).Apply(c))
+		keys = append(keys, key)
+	}
+	for _, k := range keys[1:] {
+		if k != keys[0] {
+			t.Errorf("Симметричные позиции получили разные ключи: %v", keys)
+		}
+	}
+}
+
+func TestQTraining(t *testing.T) {
+	newRules := func() Rules { return newBitRules(boardSize, winLength, false) }
+	perfect := minimaxEngine{Depth: 9}
+
+	// Необученный агент ходит в первую свободную клетку и проигрывает.
+	q := newQTable()
+	if res := playMatch(newRules, &qEngine{Table: q}, perfect, 2); res.Losses == 0 {
+		t.Errorf("Необученный агент не должен сводить вничью, но получено %+v", res)
+	}
+
+	// После игры с самим собой агент не проигрывает полному перебору.
+	q.train(15000, nil, rand.New(rand.NewSource(1)))
+	if q.Episodes != 15000 {
+		t.Errorf("Ожидалось 15000 партий, но получено %d", q.Episodes)
+	}
+	if res := playMatch(newRules, &qEngine{Table: q}, perfect, 2); res.Losses != 0 {
+		t.Errorf("Обученный агент проиграл: %+v", res)
+	}
+}
+
+func TestQLearnGame(t *testing.T) {
+	// Нолики (агент) ходят в (0, 1), крестики собирают диагональ.
+	moves := []Move{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}, {Row: 0, Col: 2}, {Row: 2, Col: 2}}
+	positions := []Rules{newBitRules(boardSize, winLength, false)}
+	for _, m := range moves {
+		positions = append(positions, positions[len(positions)-1].Apply(m))
+	}
+
+	q := newQTable()
+	q.learnGame(positions, Circle)
+	if v := q.value(positions[3], moves[3]); v >= 0 {
+		t.Errorf("Последний ход проигравшего агента должен оцениваться ниже нуля, но получено %v", v)
+	}
+	// Оценка первого хода опирается на лучший ответ в следующей позиции, а другие ходы там ещё не пробовались.
+	if v := q.value(positions[1], moves[1]); v != 0 {
+		t.Errorf("Оценка первого хода не должна меняться, но получено %v", v)
+	}
+	if q.Episodes != 1 {
+		t.Errorf("Ожидалась 1 партия, но получено %d", q.Episodes)
+	}
+}
+
+func TestQTableSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "q", "table.json")
+	q, err := loadQTable(path)
+	if err != nil || len(q.Q) != 0 {
+		t.Fatalf("Отсутствующий файл должен давать пустую таблицу: %v, %v", q, err)
+	}
+
+	q.train(100, nil, rand.New(rand.NewSource(1)))
+	if err := q.save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadQTable(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Episodes != q.Episodes || len(loaded.Q) != len(q.Q) {
+		t.Errorf("Таблица изменилась после сохранения: %d/%d и %d/%d", loaded.Episodes, len(loaded.Q), q.Episodes, len(q.Q))
+	}
+	for k, v := range q.Q {
+		if loaded.Q[k] != v {
+			t.Fatalf("Оценки позиции %d изменились", k)
+		}
+	}
+}
@@ -1,13 +1,185 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"github.com/hajimehoshi/ebiten"
 	"log"
+	"os"
+	"runtime"
+	"strings"
 )
 
 func main() {
-	resetGame()
-	if err := ebiten.Run(update, screenWidth, screenHeight, 2, "Крестики нолики"); err != nil {
+	path, err := defaultStatsPath()
+	if err != nil {
+		log.Println(err)
+	}
+	statsFile = path
+	if qTableFile, err = defaultQTablePath(); err != nil {
+		log.Println(err)
+	}
+
+	// Первый аргумент, не начинающийся с "-", — команда: stats, play, analyze, puzzle,
+	// tournament, train, evaluate, book, protocol или запуск окна по умолчанию.
+	command, args := "", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	flag.StringVar(&gameMode, "mode", modeClassic, "режим игры: "+strings.Join(variantNames(), ", "))
+	flag.BoolVar(&misere, "misere", false, "мизер: собравший линию проигрывает (режим classic)")
+	flag.IntVar(&classicSize, "size", boardSize, "размер поля в режиме classic")
+	flag.IntVar(&classicK, "k", winLength, "длина выигрышной линии в режиме classic")
+	flag.StringVar(&engineSpec, "engine", "", "движок компьютера: minimax[:глубина][:время], mcts[:итерации|время] или q (агент Q-обучения, поле 3×3) или random (случайные ходы); по умолчанию задаётся -difficulty")
+	flag.BoolVar(&vsComputer, "ai", false, "игра с компьютером в режиме classic; включается сама для -engine q")
+	flag.StringVar(&level, "difficulty", "hard", "уровень сложности: easy, medium или hard")
+	flag.IntVar(&searchWorkers, "workers", runtime.NumCPU(), "число горутин перебора minimax")
+	weightsPath := flag.String("weights", "", "JSON-файл с весами эвристики режима classic")
+	flag.StringVar(&qTableFile, "qtable", qTableFile, "файл таблицы агента Q-обучения")
+	opponentSpec := flag.String("opponent", "", "соперник в командах tournament (по умолчанию mcts), evaluate (minimax) и train (self — игра с собой)")
+	games := flag.Int("games", 10, "число партий в командах tournament и evaluate")
+	episodes := flag.Int("episodes", 50000, "число обучающих партий в команде train")
+	asJSON := flag.Bool("json", false, "вывод в JSON: разбора партии командой analyze, набора задач командой puzzle")
+	clockSpec := flag.String("clock", "", "контроль времени: 5m на партию, 5m+3s с добавкой за ход, 10s/move на ход; части сочетаются через запятую")
+	flag.StringVar(&puzzleFile, "puzzles", "", "JSON-файл с задачами; по умолчанию задачи создаются для поля -size и -k")
+	ties := flag.String("ties", "first", "выбор среди равных ходов: first — первый, random — случайный")
+	style := flag.String("style", "", "веса стиля для выбора среди равных ходов, например center:3,corner:2,edge:1; включают -ties random")
+	bookPath := flag.String("book", "", "дебютная книга компьютера: текстовый или JSON-файл")
+	plies := flag.Int("plies", 2, "глубина дебютной книги в полуходах для команды book")
+	seed := flag.Int64("seed", 0, "начальное значение случайных ходов, обучения и задач; 0 — текущее время")
+	flag.CommandLine.Parse(args)
+	log.Printf("seed %d", seedRandom(*seed))
+	switch {
+	case *style != "":
+		w, err := parseStyle(*style)
+		if err != nil {
+			log.Fatal(err)
+		}
+		tieStyle = &w
+	case *ties == "random":
+		tieStyle = &StyleWeights{}
+	case *ties != "first":
+		log.Fatalf("неизвестный выбор среди равных ходов %q", *ties)
+	}
+	v, ok := variants[gameMode]
+	if !ok {
+		log.Fatalf("неизвестный режим %q", gameMode)
+	}
+	if timeControl, err = parseTimeControl(*clockSpec); err != nil {
 		log.Fatal(err)
 	}
+	if searchWorkers < 1 {
+		log.Fatalf("недопустимое число горутин %d", searchWorkers)
+	}
+	if classicSize < 3 || classicSize > 15 || classicK < 3 || classicK > classicSize {
+		log.Fatalf("недопустимое поле %d×%d с линией %d", classicSize, classicSize, classicK)
+	}
+	if *weightsPath != "" {
+		if heuristicWeights, err = loadWeights(*weightsPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *bookPath != "" && command != "book" {
+		if openingBook, err = loadBook(*bookPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	engine, err := newEngine(engineSpec, level, v.depth)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, ok := engine.(*qEngine); ok {
+		if !isQBoard(v.newRules()) {
+			log.Fatal("агент Q-обучения играет только в режиме classic на поле 3×3")
+		}
+		vsComputer = true
+	}
+
+	if command == "puzzle" || gameMode == modePuzzle {
+		if _, err := sharedPuzzles(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	switch command {
+	case "stats":
+		if err := runStats(os.Stdout, statsFile); err != nil {
+			log.Fatal(err)
+		}
+	case "play":
+		if err := runPlay(os.Stdin, os.Stdout, v.newRules(), engine); err != nil {
+			log.Fatal(err)
+		}
+	case "analyze":
+		if err := runAnalyze(os.Stdin, os.Stdout, v.newRules(), *asJSON); err != nil {
+			log.Fatal(err)
+		}
+	case "puzzle":
+		if *asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(puzzles.Puzzles); err != nil {
+				log.Fatal(err)
+			}
+			break
+		}
+		if err := runPuzzles(os.Stdin, os.Stdout, puzzles, engine); err != nil {
+			log.Fatal(err)
+		}
+	case "tournament":
+		opponent, err := newEngine(withDefault(*opponentSpec, "mcts"), level, v.depth)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := runTournament(os.Stdout, v.newRules, engine, opponent, *games); err != nil {
+			log.Fatal(err)
+		}
+	case "train":
+		var opponent Engine
+		if spec := withDefault(*opponentSpec, "self"); spec != "self" {
+			if opponent, err = newEngine(spec, level, boardSize*boardSize); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if err := runTrain(os.Stdout, *episodes, opponent, gameRand); err != nil {
+			log.Fatal(err)
+		}
+	case "evaluate":
+		learner, err := newEngine("q", level, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opponent, err := newEngine(withDefault(*opponentSpec, "minimax"), level, boardSize*boardSize)
+		if err != nil {
+			log.Fatal(err)
+		}
+		newRules := func() Rules { return newBitRules(boardSize, winLength, false) }
+		if err := runTournament(os.Stdout, newRules, learner, opponent, *games); err != nil {
+			log.Fatal(err)
+		}
+	case "book":
+		if err := runBook(os.Stdout, v.newRules(), *plies, engine); err != nil {
+			log.Fatal(err)
+		}
+	case "protocol":
+		if err := runProtocol(os.Stdin, os.Stdout, gameMode, engineSpec, level); err != nil {
+			log.Fatal(err)
+		}
+	case "":
+		resetGame()
+		if err := ebiten.Run(update, screenWidth, screenHeight, 2, "Крестики нолики"); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("неизвестная команда %q", command)
+	}
+}
+
+// withDefault возвращает s или def, если s пустая.
+func withDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
 }
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	// uctExploration — коэффициент исследования в формуле UCT.
+	uctExploration = math.Sqrt2
+	// rolloutLimit ограничивает случайную доигровку: партии с переносом фишек могут идти долго.
+	rolloutLimit = 1000
+	// mctsSeed — начальное значение генератора движка без Rand, чтобы его ходы были воспроизводимы.
+	mctsSeed = 1
+	// mctsCheckInterval — через сколько итераций поиск проверяет отмену и время.
+	mctsCheckInterval = 64
+)
+
+// mctsEngine ищет ход методом Монте-Карло по дереву (UCT). Поиск останавливается
+// после Iterations итераций или по истечении Budget, если число итераций не задано.
+// Каждый поиск берёт начальное значение своего генератора из Rand.
+type mctsEngine struct {
+	Iterations int
+	Budget     time.Duration
+	Rand       *rand.Rand
+}
+
+func (e *mctsEngine) Name() string {
+	if e.Iterations > 0 {
+		return fmt.Sprintf("mcts:%d", e.Iterations)
+	}
+	return "mcts:" + e.Budget.String()
+}
+
+// mctsNode — узел дерева поиска. Wins считаются для игрока Mover, сделавшего ход Move.
+type mctsNode struct {
+	Move     Move
+	Mover    Player
+	State    Rules
+	Parent   *mctsNode
+	Children []*mctsNode
+	Untried  []Move
+	Visits   int
+	Wins     float64 // Выигрыш — 1, ничья — 0.5.
+}
+
+func newMCTSNode(r Rules, parent *mctsNode, m Move) *mctsNode {
+	n := &mctsNode{Move: m, State: r, Parent: parent, Untried: r.LegalMoves()}
+	if parent != nil {
+		n.Mover = parent.State.ToMove()
+	}
+	return n
+}
+
+// selectChild выбирает потомка с наибольшей оценкой UCT.
+func (n *mctsNode) selectChild() *mctsNode {
+	var best *mctsNode
+	bestScore := math.Inf(-1)
+	logVisits := math.Log(float64(n.Visits))
+	for _, c := range n.Children {
+		score := c.Wins/float64(c.Visits) + uctExploration*math.Sqrt(logVisits/float64(c.Visits))
+		if score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// rollout доигрывает партию случайными ходами и возвращает победителя.
+func rollout(r Rules, rnd *rand.Rand) Player {
+	for i := 0; i < rolloutLimit && !r.Terminal(); i++ {
+		moves := r.LegalMoves()
+		r = r.Apply(moves[rnd.Intn(len(moves))])
+	}
+	if !r.Terminal() {
+		return Empty
+	}
+	return r.Winner()
+}
+
+// BestMove возвращает самый исследованный ход. В SearchInfo Nodes — число итераций,
+// Depth — наибольшая глубина дерева.
+func (e *mctsEngine) BestMove(ctx context.Context, r Rules) (Move, SearchInfo, bool) {
+	start := time.Now()
+	root := newMCTSNode(r, nil, Move{})
+	if len(root.Untried) == 0 {
+		return Move{}, SearchInfo{}, false
+	}
+
+	seed := int64(mctsSeed)
+	if e.Rand != nil {
+		seed = e.Rand.Int63()
+	}
+	rnd := rand.New(rand.NewSource(seed))
+	deadline := start.Add(e.Budget)
+	var info SearchInfo
+	// Хотя бы одна итерация нужна, чтобы у корня появился потомок.
+	for ; ; info.Nodes++ {
+		if info.Nodes > 0 && e.Iterations > 0 && info.Nodes >= e.Iterations {
+			break
+		}
+		if info.Nodes%mctsCheckInterval == 0 {
+			if ctx.Err() != nil {
+				return Move{}, info, false
+			}
+			if info.Nodes > 0 && e.Iterations <= 0 && time.Now().After(deadline) {
+				break
+			}
+		}
+
+		// Спуск по дереву до узла с неопробованными ходами.
+		n, depth := root, 0
+		for len(n.Untried) == 0 && len(n.Children) > 0 {
+			n = n.selectChild()
+			depth++
+		}
+
+		// Расширение одним случайным ходом.
+		if len(n.Untried) > 0 {
+			k := rnd.Intn(len(n.Untried))
+			m := n.Untried[k]
+			n.Untried[k] = n.Untried[len(n.Untried)-1]
+			n.Untried = n.Untried[:len(n.Untried)-1]
+			child := newMCTSNode(n.State.Apply(m), n, m)
+			n.Children = append(n.Children, child)
+			n = child
+			depth++
+		}
+		info.Depth = max(info.Depth, depth)
+
+		// Доигровка и обновление статистики на пути к корню.
+		w := rollout(n.State, rnd)
+		for ; n != nil; n = n.Parent {
+			n.Visits++
+			switch w {
+			case Empty:
+				n.Wins += 0.5
+			case n.Mover:
+				n.Wins++
+			}
+		}
+	}
+
+	// Выбираем самый исследованный ход: он надёжнее хода с лучшей средней оценкой.
+	best := root.Children[0]
+	for _, c := range root.Children[1:] {
+		if c.Visits > best.Visits {
+			best = c
+		}
+	}
+	info.Elapsed = time.Since(start)
+	return best.Move, info, true
+}
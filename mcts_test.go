@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMCTSFindsWinAndBlock(t *testing.T) {
+	e := &mctsEngine{Iterations: 2000}
+
+	// Крестики выигрывают ходом в (0, 2).
+	r := Rules(newClassicRules(boardSize, winLength, false))
+	for _, m := range []Move{{Row: 0, Col: 0}, {Row: 1, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}} {
+		r = r.Apply(m)
+	}
+	if m, _, ok := e.BestMove(context.Background(), r); !ok || m.Row != 0 || m.Col != 2 {
+		t.Errorf("Ожидался выигрышный ход (0, 2), но получен %v", m)
+	}
+
+	// Нолики обязаны закрыть (0, 2).
+	r = newClassicRules(boardSize, winLength, false)
+	for _, m := range []Move{{Row: 0, Col: 0}, {Row: 1, Col: 1}, {Row: 0, Col: 1}} {
+		r = r.Apply(m)
+	}
+	if m, _, ok := e.BestMove(context.Background(), r); !ok || m.Row != 0 || m.Col != 2 {
+		t.Errorf("Ожидалась защита в (0, 2), но получен %v", m)
+	}
+}
+
+func TestMCTSPerfectPlay(t *testing.T) {
+	// При достаточном числе итераций MCTS не проигрывает полному перебору ни одним цветом.
+	newRules := func() Rules { return newClassicRules(boardSize, winLength, false) }
+	res := playMatch(newRules, &mctsEngine{Iterations: 20000}, minimaxEngine{Depth: 9}, 2)
+	if res.Draws != 2 {
+		t.Errorf("Ожидались две ничьи, но получено %+v", res)
+	}
+}
+
+func TestMCTSBudget(t *testing.T) {
+	e := &mctsEngine{Budget: 20 * time.Millisecond}
+	start := time.Now()
+	_, info, ok := e.BestMove(context.Background(), newClassicRules(5, 4, false))
+	if !ok || info.Nodes == 0 || info.Depth == 0 {
+		t.Fatalf("Движок не нашёл хода на пустом поле: %+v", info)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Поиск занял %v при бюджете %v", elapsed, e.Budget)
+	}
+}
+
+func TestMCTSTerminal(t *testing.T) {
+	// Крестики уже собрали верхнюю строку.
+	r := Rules(newClassicRules(boardSize, winLength, false))
+	for _, m := range []Move{{Row: 0, Col: 0}, {Row: 1, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}, {Row: 0, Col: 2}} {
+		r = r.Apply(m)
+	}
+	if m, _, ok := (&mctsEngine{Iterations: 100}).BestMove(context.Background(), r); ok {
+		t.Errorf("В окончившейся партии не должно быть хода, но получен %v", m)
+	}
+}
+
+func BenchmarkMCTS3x3(b *testing.B) {
+	e := &mctsEngine{Iterations: 10000}
+	for i := 0; i < b.N; i++ {
+		e.BestMove(context.Background(), newClassicRules(3, 3, false))
+	}
+}
+
+func BenchmarkMCTS5x5(b *testing.B) {
+	e := &mctsEngine{Iterations: 10000}
+	for i := 0; i < b.N; i++ {
+		e.BestMove(context.Background(), newClassicRules(5, 4, false))
+	}
+}
+
+func BenchmarkMinimax3x3(b *testing.B) {
+	e := minimaxEngine{Depth: 9}
+	for i := 0; i < b.N; i++ {
+		e.BestMove(context.Background(), newClassicRules(3, 3, false))
+	}
+}
+
+func TestMCTSSeed(t *testing.T) {
+	// Движок берёт генератор из gameRand: одно начальное значение — одна и та же партия.
+	defer seedRandom(1)
+	play := func(seed int64) []Move {
+		seedRandom(seed)
+		e, err := parseEngine("mcts:50", boardSize*boardSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var moves []Move
+		for r := Rules(newBitRules(boardSize, winLength, false)); !r.Terminal(); {
+			m, _, _ := e.BestMove(context.Background(), r)
+			moves = append(moves, m)
+			r = r.Apply(m)
+		}
+		return moves
+	}
+	if a, b := play(3), play(3); !reflect.DeepEqual(a, b) {
+		t.Errorf("Партии с одним начальным значением различаются: %v и %v", a, b)
+	}
+	differs := false
+	for seed := int64(4); seed < 10 && !differs; seed++ {
+		differs = !reflect.DeepEqual(play(3), play(seed))
+	}
+	if !differs {
+		t.Error("Начальное значение не влияет на ходы MCTS")
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"sync"
+)
+
+const (
+	morrisPieces      = 3
+	morrisRepetitions = 3
+)
+
+// noCell обозначает отсутствие клетки: ход из noCell — выставление новой фишки.
+var noCell = Cell{-1, -1}
+
+// MorrisMove задаёт ход в «мельнице трёх»: выставление фишки (From == noCell) или перенос из From в To.
+type MorrisMove struct {
+	From, To Cell
+}
+
+// MorrisBoard хранит позицию «мельницы трёх» на поле 3×3.
+type MorrisBoard struct {
+	Cells [boardSize][boardSize]Player
+	Turn  Player
+}
+
+// MorrisGame — партия с историей позиций для определения ничьей повторением.
+type MorrisGame struct {
+	Board   MorrisBoard
+	History map[int]int
+}
+
+// newMorrisGame создаёт пустую партию, в которой первыми ходят крестики.
+func newMorrisGame() MorrisGame {
+	g := MorrisGame{Board: MorrisBoard{Turn: Cross}, History: make(map[int]int)}
+	g.History[g.Board.key()]++
+	return g
+}
+
+// key кодирует позицию целым числом в троичной записи: 9 клеток и сторона, которая ходит.
+func (b *MorrisBoard) key() int {
+	k := 0
+	for i := 0; i < boardSize; i++ {
+		for j := 0; j < boardSize; j++ {
+			k = k*3 + int(b.Cells[i][j])
+		}
+	}
+	return k*3 + int(b.Turn)
+}
+
+// morrisFromKey восстанавливает позицию из ключа.
+func morrisFromKey(k int) MorrisBoard {
+	var b MorrisBoard
+	b.Turn = Player(k % 3)
+	k /= 3
+	for n := boardSize*boardSize - 1; n >= 0; n-- {
+		b.Cells[n/boardSize][n%boardSize] = Player(k % 3)
+		k /= 3
+	}
+	return b
+}
+
+// pieces возвращает число фишек игрока p на поле.
+func (b *MorrisBoard) pieces(p Player) int {
+	n := 0
+	for i := 0; i < boardSize; i++ {
+		for j := 0; j < boardSize; j++ {
+			if b.Cells[i][j] == p {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Winner возвращает игрока, выстроившего три фишки в ряд.
+func (b *MorrisBoard) Winner() Player {
+	if checkWin(b.Cells, Circle) {
+		return Circle
+	}
+	if checkWin(b.Cells, Cross) {
+		return Cross
+	}
+	return Empty
+}
+
+// legalMoves возвращает ходы стороны, которая ходит: пока выставлены не все фишки —
+// выставление в пустую клетку, затем перенос своей фишки в любую пустую клетку.
+func (b *MorrisBoard) legalMoves() []MorrisMove {
+	var moves []MorrisMove
+	if b.Winner() != Empty {
+		return moves
+	}
+
+	var own, empty []Cell
+	for i := 0; i < boardSize; i++ {
+		for j := 0; j < boardSize; j++ {
+			switch b.Cells[i][j] {
+			case Empty:
+				empty = append(empty, Cell{i, j})
+			case b.Turn:
+				own = append(own, Cell{i, j})
+			}
+		}
+	}
+
+	if len(own) < morrisPieces {
+		for _, to := range empty {
+			moves = append(moves, MorrisMove{From: noCell, To: to})
+		}
+		return moves
+	}
+	for _, from := range own {
+		for _, to := range empty {
+			moves = append(moves, MorrisMove{From: from, To: to})
+		}
+	}
+	return moves
+}
+
+// play возвращает позицию после хода m.
+func (b MorrisBoard) play(m MorrisMove) MorrisBoard {
+	if m.From != noCell {
+		b.Cells[m.From.Row][m.From.Col] = Empty
+	}
+	b.Cells[m.To.Row][m.To.Col] = b.Turn
+	b.Turn = other(b.Turn)
+	return b
+}
+
+// play делает ход в партии и запоминает новую позицию в истории.
+// История копируется, чтобы позиции, из которых сделан ход, не менялись.
+func (g *MorrisGame) play(m MorrisMove) {
+	history := make(map[int]int, len(g.History)+1)
+	for k, v := range g.History {
+		history[k] = v
+	}
+	g.Board = g.Board.play(m)
+	history[g.Board.key()]++
+	g.History = history
+}
+
+// Repeated сообщает о ничьей: текущая позиция встретилась morrisRepetitions раз.
+func (g *MorrisGame) Repeated() bool {
+	return g.History[g.Board.key()] >= morrisRepetitions
+}
+
+// MorrisValue — результат позиции для стороны, которая ходит, и число полуходов до конца.
+type MorrisValue struct {
+	Result int // 1 — выигрыш, -1 — проигрыш, 0 — ничья при правильной игре.
+	Plies  int
+}
+
+var (
+	morrisTable     map[int]MorrisValue
+	morrisTableOnce sync.Once
+)
+
+// solveMorris решает игру ретроградным анализом. В отличие от минимакса,
+// который спускается от позиции к листьям, анализ идёт от проигранных позиций
+// назад, поэтому циклы ходов не мешают: позиции, которые так и не получили
+// оценку, — ничьи, из которых ни одна сторона не может форсировать выигрыш.
+func solveMorris() map[int]MorrisValue {
+	// Перебираем все допустимые по числу фишек позиции. Крестики ходят первыми,
+	// поэтому при выставлении сторону хода определяет число фишек, а после него ходить может любая сторона.
+	var states []MorrisBoard
+	for code := 0; code < 19683; code++ {
+		b := morrisFromKey(code * 3)
+		crosses, circles := b.pieces(Cross), b.pieces(Circle)
+		if crosses > morrisPieces || circles > morrisPieces {
+			continue
+		}
+
+		var turns []Player
+		switch {
+		case crosses == morrisPieces && circles == morrisPieces:
+			turns = []Player{Cross, Circle}
+		case crosses == circles:
+			turns = []Player{Cross}
+		case crosses == circles+1:
+			turns = []Player{Circle}
+		}
+		for _, turn := range turns {
+			b.Turn = turn
+			// У стороны, которая ходит, не может уже быть линии: после неё успел сходить соперник.
+			if !checkWin(b.Cells, turn) {
+				states = append(states, b)
+			}
+		}
+	}
+
+	table := make(map[int]MorrisValue)
+	for _, b := range states {
+		if b.Winner() != Empty {
+			table[b.key()] = MorrisValue{Result: -1}
+		}
+	}
+
+	for plies := 1; ; plies++ {
+		found := make(map[int]MorrisValue)
+		for _, b := range states {
+			if _, ok := table[b.key()]; ok {
+				continue
+			}
+			allLose := true
+			for _, m := range b.legalMoves() {
+				next := b.play(m)
+				v, ok := table[next.key()]
+				if ok && v.Result == -1 && v.Plies == plies-1 {
+					found[b.key()] = MorrisValue{Result: 1, Plies: plies}
+					allLose = false
+					break
+				}
+				if !ok || v.Result != 1 {
+					allLose = false
+				}
+			}
+			if allLose {
+				found[b.key()] = MorrisValue{Result: -1, Plies: plies}
+			}
+		}
+		if len(found) == 0 {
+			break
+		}
+		for k, v := range found {
+			table[k] = v
+		}
+	}
+	return table
+}
+
+// morrisValue возвращает точную оценку позиции.
+func morrisValue(b *MorrisBoard) MorrisValue {
+	morrisTableOnce.Do(func() { morrisTable = solveMorris() })
+	return morrisTable[b.key()]
+}
+
+func (g *MorrisGame) Name() string {
+	return modeMorris
+}
+
+func (g *MorrisGame) ToMove() Player {
+	return g.Board.Turn
+}
+
+func (g *MorrisGame) At(c Cell3) Player {
+	return g.Board.Cells[c.Row][c.Col]
+}
+
+func (g *MorrisGame) LegalMoves() []Move {
+	var moves []Move
+	if g.Repeated() {
+		return moves
+	}
+	for _, m := range g.Board.legalMoves() {
+		moves = append(moves, Move{Row: m.To.Row, Col: m.To.Col, From: m.From, Shift: m.From != noCell, Symbol: g.Board.Turn})
+	}
+	return moves
+}
+
+func (g *MorrisGame) Apply(m Move) Rules {
+	from := noCell
+	if m.Shift {
+		from = m.From
+	}
+	next := *g
+	next.play(MorrisMove{From: from, To: Cell{m.Row, m.Col}})
+	return &next
+}
+
+func (g *MorrisGame) Terminal() bool {
+	return g.Board.Winner() != Empty || g.Repeated()
+}
+
+func (g *MorrisGame) Winner() Player {
+	return g.Board.Winner()
+}
+
+// Evaluate берёт точную оценку из таблицы ретроградного анализа,
+// поэтому для правильной игры достаточно перебора на один полуход.
+func (g *MorrisGame) Evaluate() int {
+	v := morrisValue(&g.Board)
+	if v.Result == 0 {
+		return 0
+	}
+	return v.Result * (winScore/2 - v.Plies)
+}
+
+func (g *MorrisGame) Hints() RenderHints {
+	h := RenderHints{Layers: 1, Rows: boardSize, Cols: boardSize, Shift: true}
+	_, line := findLine(func(row, col int) Player { return g.Board.Cells[row][col] }, boardSize, boardSize, boardSize)
+	for _, c := range line {
+		h.Line = append(h.Line, Cell3{0, c.Row, c.Col})
+	}
+	if g.Board.Winner() == Empty && g.Repeated() {
+		h.Note = "repetition"
+	}
+	return h
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestMorrisMoves(t *testing.T) {
+	g := newMorrisGame()
+
+	// Пока выставлены не все фишки, можно ходить в любую пустую клетку.
+	if moves := g.Board.legalMoves(); len(moves) != 9 {
+		t.Fatalf("Ожидалось 9 ходов, но получено %d", len(moves))
+	}
+
+	for _, c := range []Cell{{0, 0}, {1, 1}, {0, 2}, {0, 1}, {2, 0}, {2, 2}} {
+		g.play(MorrisMove{From: noCell, To: c})
+	}
+
+	// После выставления трёх фишек остаются только переносы: 3 фишки × 3 пустые клетки.
+	moves := g.Board.legalMoves()
+	if len(moves) != 9 {
+		t.Fatalf("Ожидалось 9 ходов, но получено %d", len(moves))
+	}
+	for _, m := range moves {
+		if m.From == noCell || g.Board.Cells[m.From.Row][m.From.Col] != Cross {
+			t.Errorf("Ход %v должен переносить фишку крестиков", m)
+		}
+	}
+
+	// Перенос (2, 0) → (1, 0) строит вертикаль крестиков.
+	g.play(MorrisMove{From: Cell{2, 0}, To: Cell{1, 0}})
+	if g.Board.pieces(Cross) != morrisPieces {
+		t.Errorf("Перенос не должен менять число фишек")
+	}
+}
+
+func TestMorrisRepetition(t *testing.T) {
+	g := newMorrisGame()
+	for _, c := range []Cell{{0, 0}, {1, 1}, {0, 2}, {0, 1}, {2, 1}, {1, 0}} {
+		g.play(MorrisMove{From: noCell, To: c})
+	}
+
+	// Обе стороны переставляют фишку туда и обратно.
+	shuffle := []MorrisMove{
+		{From: Cell{2, 1}, To: Cell{2, 2}},
+		{From: Cell{1, 0}, To: Cell{2, 0}},
+		{From: Cell{2, 2}, To: Cell{2, 1}},
+		{From: Cell{2, 0}, To: Cell{1, 0}},
+	}
+	for round := 1; round < morrisRepetitions; round++ {
+		if g.Repeated() {
+			t.Fatalf("Ничья зафиксирована слишком рано, на круге %d", round)
+		}
+		for _, m := range shuffle {
+			g.play(m)
+		}
+	}
+	if !g.Repeated() {
+		t.Errorf("Ожидалась ничья повторением позиции")
+	}
+}
+
+func TestMorrisSolver(t *testing.T) {
+	// Начальная позиция при правильной игре — ничья.
+	g := newMorrisGame()
+	if v := morrisValue(&g.Board); v.Result != 0 {
+		t.Errorf("Ожидалась ничья, но получено %v", v)
+	}
+
+	// Крестики выигрывают за один ход, перенеся фишку из (2, 2) в (0, 2).
+	b := MorrisBoard{
+		Cells: [3][3]Player{
+			{Cross, Cross, Empty},
+			{Circle, Circle, Empty},
+			{Circle, Empty, Cross},
+		},
+		Turn: Cross,
+	}
+	if v := morrisValue(&b); v.Result != 1 || v.Plies != 1 {
+		t.Errorf("Ожидался выигрыш в 1 полуход, но получено %v", v)
+	}
+	g = MorrisGame{Board: b, History: map[int]int{}}
+	m, _, ok := bestMove(&g, 1)
+	expected := Move{Row: 0, Col: 2, From: Cell{2, 2}, Shift: true, Symbol: Cross}
+	if !ok || m != expected {
+		t.Errorf("Ожидался ход %v, но получен %v", expected, m)
+	}
+
+	// Нолики в той же расстановке должны помешать, закрыв (0, 2) или построив свою линию.
+	g.Board.Turn = Circle
+	m, _, ok = bestMove(&g, 1)
+	next := g.Apply(m)
+	if !ok || next.Winner() != Circle && next.At(Cell3{0, 0, 2}) != Circle {
+		t.Errorf("Ход %v не защищает от угрозы крестиков", m)
+	}
+}
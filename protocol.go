@@ -0,0 +1,371 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// protocolNoLimit — время на поиск без ограничения времени, больше любой партии.
+	protocolNoLimit = 100 * 365 * 24 * time.Hour
+	// protocolMaxDepth — глубина поиска infinite: перебор идёт до команды stop
+	// или пока не будут доиграны все партии, но bestmove выводится только после stop.
+	protocolMaxDepth = 1000
+)
+
+// protocolEngine — движок в текстовом протоколе для внешних программ, похожем на UCI.
+// Команды читаются построчно, ответы пишутся построчно:
+//
+//	protocol                           -> id name ..., id variants ..., protocolok
+//	isready                            -> readyok
+//	newgame [вариант]                  новая партия, по умолчанию в текущем варианте
+//	position startpos [moves ход ...]  начальная позиция и сделанные из неё ходы
+//	go [depth N] [movetime MS] [xtime MS] [otime MS] [xinc MS] [oinc MS] [infinite]
+//	                                   -> info depth ... pv ..., bestmove ход
+//	stop                               прервать поиск и сразу ответить bestmove
+//	quit                               завершить работу
+//
+// Ход выбирает движок, заданный -engine или -difficulty, с дебютной книгой -book.
+// Перебор minimax после каждой глубины выводит строку
+// "info depth D score S nodes N time MS pv ход ...", где S — оценка для ходящего:
+// число, "win P" или "loss P" — выигрыш или проигрыш через P полуходов.
+// Команды newgame, position и go прерывают идущий поиск, как stop; конец ввода — как quit.
+// После go infinite ответ bestmove ждёт stop, quit или конца ввода, даже если поиск уже закончен.
+// Ошибки и сообщения выводятся строками "info string ...".
+type protocolEngine struct {
+	out  io.Writer
+	outM sync.Mutex
+
+	spec, level string // Описание движка и уровень сложности, как в флагах -engine и -difficulty.
+	mode        string
+	r           Rules
+	engine      Engine
+	// cancel прерывает идущий поиск, done закрывается, когда он закончен.
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// goLimits — ограничения поиска из команды go. Нулевые поля не действуют.
+type goLimits struct {
+	Depth    int
+	MoveTime time.Duration
+	Time     map[Player]time.Duration
+	Inc      map[Player]time.Duration
+	Infinite bool
+}
+
+// runProtocol реализует подкоманду "protocol": читает команды из in и отвечает в out,
+// начиная партию в варианте mode. Ходы выбирает движок spec или уровня сложности level.
+func runProtocol(in io.Reader, out io.Writer, mode, spec, level string) error {
+	p := &protocolEngine{out: out, spec: spec, level: level}
+	if err := p.newGame(mode); err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" {
+			p.stop()
+			return nil
+		}
+		p.command(fields[0], fields[1:])
+	}
+	p.stop()
+	return scanner.Err()
+}
+
+// send выводит строку ответа; ответы поиска и команд не перемешиваются.
+func (p *protocolEngine) send(format string, args ...any) {
+	p.outM.Lock()
+	defer p.outM.Unlock()
+	fmt.Fprintf(p.out, format+"\n", args...)
+}
+
+// command выполняет одну команду протокола, кроме quit.
+func (p *protocolEngine) command(name string, args []string) {
+	switch name {
+	case "protocol":
+		p.send("id name lab3sem2")
+		p.send("id variants %s", strings.Join(protocolVariants(), " "))
+		p.send("protocolok")
+	case "isready":
+		p.send("readyok")
+	case "newgame":
+		p.stop()
+		mode := p.mode
+		if len(args) > 0 {
+			mode = args[0]
+		}
+		if err := p.newGame(mode); err != nil {
+			p.send("info string error: %v", err)
+		}
+	case "position":
+		p.stop()
+		r, err := p.position(args)
+		if err != nil {
+			p.send("info string error: %v", err)
+			return
+		}
+		p.r = r
+	case "go":
+		p.stop()
+		limits, err := parseGoLimits(args)
+		if err != nil {
+			p.send("info string error: %v", err)
+			return
+		}
+		p.start(limits)
+	case "stop":
+		p.stop()
+	default:
+		p.send("info string unknown command %q", name)
+	}
+}
+
+// protocolVariants возвращает варианты, доступные в протоколе: все, кроме задач,
+// начальная позиция которых меняется от партии к партии.
+func protocolVariants() []string {
+	var names []string
+	for _, name := range variantNames() {
+		if name != modePuzzle {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// newGame начинает партию в варианте mode с новым движком, как resetGame в окне игры.
+func (p *protocolEngine) newGame(mode string) error {
+	v, ok := variants[mode]
+	if !ok || mode == modePuzzle {
+		return fmt.Errorf("неизвестный вариант %q", mode)
+	}
+	e, err := newEngine(p.spec, p.level, v.depth)
+	if err != nil {
+		return err
+	}
+	r := v.newRules()
+	if _, ok := e.(*qEngine); ok && !isQBoard(r) {
+		return fmt.Errorf("агент Q-обучения не играет в варианте %s", mode)
+	}
+	p.mode, p.r, p.engine = mode, r, e
+	return nil
+}
+
+// position строит позицию по аргументам команды position: "startpos [moves ход ...]".
+func (p *protocolEngine) position(args []string) (Rules, error) {
+	if len(args) == 0 || args[0] != "startpos" {
+		return nil, fmt.Errorf("ожидалось startpos")
+	}
+	r := variants[p.mode].newRules()
+	args = args[1:]
+	if len(args) == 0 {
+		return r, nil
+	}
+	if args[0] != "moves" {
+		return nil, fmt.Errorf("ожидалось moves, а не %q", args[0])
+	}
+	for i, s := range args[1:] {
+		if r.Terminal() {
+			return nil, fmt.Errorf("ход %d: партия уже окончена", i+1)
+		}
+		m, err := parseMove(r, s)
+		if err != nil {
+			return nil, fmt.Errorf("ход %d: %w", i+1, err)
+		}
+		r = r.Apply(m)
+	}
+	return r, nil
+}
+
+// parseGoLimits разбирает аргументы команды go; время задаётся в миллисекундах.
+func parseGoLimits(args []string) (goLimits, error) {
+	l := goLimits{Time: make(map[Player]time.Duration), Inc: make(map[Player]time.Duration)}
+	for i := 0; i < len(args); i++ {
+		name := args[i]
+		if name == "infinite" {
+			l.Infinite = true
+			continue
+		}
+		if i+1 >= len(args) {
+			return l, fmt.Errorf("у %s нет значения", name)
+		}
+		i++
+		n, err := strconv.Atoi(args[i])
+		if err != nil || n < 0 {
+			return l, fmt.Errorf("неверное значение %s %q", name, args[i])
+		}
+		ms := time.Duration(n) * time.Millisecond
+		switch name {
+		case "depth":
+			if n == 0 {
+				return l, fmt.Errorf("неверная глубина %d", n)
+			}
+			l.Depth = n
+		case "movetime":
+			l.MoveTime = ms
+		case "xtime":
+			l.Time[Cross] = ms
+		case "otime":
+			l.Time[Circle] = ms
+		case "xinc":
+			l.Inc[Cross] = ms
+		case "oinc":
+			l.Inc[Circle] = ms
+		default:
+			return l, fmt.Errorf("неизвестный параметр %q", name)
+		}
+	}
+	return l, nil
+}
+
+// budget возвращает время на поиск хода игрока p: movetime или долю времени на часах,
+// как у компьютера в партии с часами. Ноль — поиск без ограничения времени.
+func (l goLimits) budget(p Player) time.Duration {
+	if l.Infinite {
+		return 0
+	}
+	budget := l.MoveTime
+	if total, ok := l.Time[p]; ok {
+		c := newClock(TimeControl{Total: total, Increment: l.Inc[p]}, time.Now)
+		c.Start(p)
+		if b := c.Budget(p); budget == 0 || b < budget {
+			budget = b
+		}
+	}
+	return budget
+}
+
+// start запускает поиск хода в текущей позиции движком партии с ограничениями limits.
+// Поиск infinite отвечает только после отмены, то есть после stop.
+func (p *protocolEngine) start(limits goLimits) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel, p.done = cancel, make(chan struct{})
+	obs := &protocolObserver{p: p, r: p.r}
+	e := limits.apply(p.engine, p.r.ToMove(), obs)
+	go func(r Rules, done chan struct{}) {
+		defer close(done)
+		defer cancel()
+		m, ok := p.search(ctx, r, e, obs)
+		if limits.Infinite {
+			<-ctx.Done()
+		}
+		if ok {
+			p.send("bestmove %s", formatMove(r, m))
+		} else {
+			p.send("bestmove (none)")
+		}
+	}(p.r, p.done)
+}
+
+// stop прерывает идущий поиск и дожидается его ответа bestmove.
+func (p *protocolEngine) stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wait()
+}
+
+// wait дожидается окончания идущего поиска.
+func (p *protocolEngine) wait() {
+	if p.done != nil {
+		<-p.done
+	}
+	p.cancel, p.done = nil, nil
+}
+
+// protocolObserver выводит итоги углубления строками info и запоминает последний
+// лучший ход, чтобы ответить им, если поиск прерван командой stop.
+type protocolObserver struct {
+	p    *protocolEngine
+	r    Rules
+	best Move
+}
+
+func (o *protocolObserver) observe(sp SearchProgress) {
+	o.best = sp.Move
+	o.p.send("info depth %d score %s nodes %d time %d pv %s",
+		sp.Info.Depth, formatScore(sp.Score, sp.Info.Depth), sp.Info.Nodes, sp.Info.Elapsed.Milliseconds(), formatLine(o.r, sp.PV))
+}
+
+// apply настраивает движок e партии под ограничения go для ходящего p. Перебор minimax
+// всегда идёт итеративным углублением, чтобы после каждой глубины выводилась строка info:
+// depth без ограничения времени снимает время на ход движка, infinite — и время, и глубину.
+// Время на ход и часы только сокращают время, заданное -engine или -difficulty.
+// Движок в дебютной книге настраивается так же, а движки без перебора не меняются.
+func (l goLimits) apply(e Engine, p Player, obs searchObserver) Engine {
+	switch e := e.(type) {
+	case *bookEngine:
+		return &bookEngine{Engine: l.apply(e.Engine, p, obs), Book: e.Book, Rand: e.Rand}
+	case minimaxEngine:
+		e.Observer = obs
+		if l.Depth > 0 {
+			e.Depth = l.Depth
+		} else if l.Infinite {
+			e.Depth = protocolMaxDepth
+		}
+		if l.Infinite || l.Depth > 0 && l.budget(p) == 0 || e.Budget == 0 {
+			e.Budget = protocolNoLimit
+		}
+		return l.timed(e, p)
+	case *mctsEngine:
+		if l.Infinite {
+			return &mctsEngine{Budget: protocolNoLimit, Rand: e.Rand}
+		}
+	}
+	return l.timed(e, p)
+}
+
+// timed ограничивает движок e временем на ход игрока p из команды go.
+func (l goLimits) timed(e Engine, p Player) Engine {
+	b, ok := e.(budgeted)
+	if d := l.budget(p); ok && d > 0 {
+		return b.withBudget(d)
+	}
+	return e
+}
+
+// search ищет ход движком e. После прерывания возвращается лучший ход последней
+// завершённой глубины, а если не завершилась ни одна — первый допустимый.
+func (p *protocolEngine) search(ctx context.Context, r Rules, e Engine, obs *protocolObserver) (Move, bool) {
+	moves := r.LegalMoves()
+	if r.Terminal() || len(moves) == 0 {
+		return Move{}, false
+	}
+	obs.best = moves[0]
+	if m, _, ok := e.BestMove(ctx, r); ok {
+		return m, true
+	}
+	return obs.best, true
+}
+
+// formatScore записывает оценку перебора на глубину depth: доказанный исход —
+// словом и числом полуходов до конца партии, остальное — числом.
+func formatScore(val, depth int) string {
+	switch {
+	case val >= winScore:
+		return fmt.Sprintf("win %d", depth-(val-winScore))
+	case val <= -winScore:
+		return fmt.Sprintf("loss %d", depth-(-val-winScore))
+	}
+	return strconv.Itoa(val)
+}
+
+// formatLine записывает ходы, сделанные подряд из позиции r, через пробел.
+func formatLine(r Rules, moves []Move) string {
+	parts := make([]string, len(moves))
+	for i, m := range moves {
+		parts[i] = formatMove(r, m)
+		r = r.Apply(m)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// protocolDriver разговаривает с движком в режиме протокола через каналы,
+// как внешняя программа: пишет команды и читает ответы построчно.
+type protocolDriver struct {
+	t     *testing.T
+	in    *io.PipeWriter
+	lines chan string
+	done  chan error
+}
+
+func newProtocolDriver(t *testing.T, mode string) *protocolDriver {
+	return newEngineDriver(t, mode, "")
+}
+
+// newEngineDriver запускает протокол с движком spec; пустое описание — уровень hard.
+func newEngineDriver(t *testing.T, mode, spec string) *protocolDriver {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	d := &protocolDriver{t: t, in: inW, lines: make(chan string, 100), done: make(chan error, 1)}
+	go func() {
+		err := runProtocol(inR, outW, mode, spec, "hard")
+		outW.Close()
+		d.done <- err
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			d.lines <- scanner.Text()
+		}
+		close(d.lines)
+	}()
+	t.Cleanup(func() {
+		inW.Close()
+		for range d.lines {
+		}
+	})
+	return d
+}
+
+func (d *protocolDriver) send(cmd string) {
+	d.t.Helper()
+	if _, err := io.WriteString(d.in, cmd+"\n"); err != nil {
+		d.t.Fatalf("Команда %q не отправлена: %v", cmd, err)
+	}
+}
+
+// read возвращает следующую строку ответа.
+func (d *protocolDriver) read() string {
+	d.t.Helper()
+	select {
+	case line, ok := <-d.lines:
+		if !ok {
+			d.t.Fatal("Движок закрыл вывод")
+		}
+		return line
+	case <-time.After(10 * time.Second):
+		d.t.Fatal("Движок не ответил за 10 секунд")
+	}
+	return ""
+}
+
+// expect читает строки до первой, начинающейся с prefix, и возвращает её вместе с пропущенными.
+func (d *protocolDriver) expect(prefix string) (string, []string) {
+	d.t.Helper()
+	var skipped []string
+	for {
+		line := d.read()
+		if strings.HasPrefix(line, prefix) {
+			return line, skipped
+		}
+		skipped = append(skipped, line)
+	}
+}
+
+var infoLine = regexp.MustCompile(`^info depth \d+ score (-?\d+|win \d+|loss \d+) nodes \d+ time \d+ pv \S+( \S+)*$`)
+
+func TestProtocolHandshake(t *testing.T) {
+	d := newProtocolDriver(t, modeClassic)
+	d.send("protocol")
+	if line := d.read(); line != "id name lab3sem2" {
+		t.Errorf("Первая строка %q", line)
+	}
+	line := d.read()
+	if !strings.HasPrefix(line, "id variants ") || !strings.Contains(line, " classic") || strings.Contains(line, modePuzzle) {
+		t.Errorf("Неверный список вариантов %q", line)
+	}
+	if line := d.read(); line != "protocolok" {
+		t.Errorf("Ожидалось protocolok, получено %q", line)
+	}
+	d.send("isready")
+	if line := d.read(); line != "readyok" {
+		t.Errorf("Ожидалось readyok, получено %q", line)
+	}
+	d.send("quit")
+	select {
+	case err := <-d.done:
+		if err != nil {
+			t.Errorf("Ошибка после quit: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Движок не завершился по quit")
+	}
+}
+
+func TestProtocolSearch(t *testing.T) {
+	d := newProtocolDriver(t, modeClassic)
+
+	// Нолики должны закрыть верхнюю строку.
+	d.send("position startpos moves 0,0 1,1 0,1")
+	d.send("go depth 9")
+	line, infos := d.expect("bestmove ")
+	if line != "bestmove 0,2" {
+		t.Errorf("Ожидалась защита 0,2, получено %q", line)
+	}
+	if len(infos) == 0 {
+		t.Fatal("Поиск не вывел ни одной строки info")
+	}
+	for _, info := range infos {
+		if !infoLine.MatchString(info) {
+			t.Errorf("Неверная строка info %q", info)
+		}
+	}
+	if last := infos[len(infos)-1]; !strings.Contains(last, " pv 0,2") || !strings.Contains(last, "score 0 ") {
+		t.Errorf("Последняя строка info %q: ожидались ничья и вариант с 0,2", last)
+	}
+
+	// Крестики выигрывают сразу.
+	d.send("position startpos moves 0,0 1,0 0,1 1,1")
+	d.send("go")
+	line, infos = d.expect("bestmove ")
+	if line != "bestmove 0,2" || len(infos) == 0 || !strings.Contains(infos[len(infos)-1], "score win 1 ") {
+		t.Errorf("Ожидался выигрыш 0,2 за полуход, получено %q после %q", line, infos)
+	}
+
+	d.send("position startpos moves 0,0 1,0 0,1 1,1 0,2")
+	d.send("go")
+	if line, _ := d.expect("bestmove "); line != "bestmove (none)" {
+		t.Errorf("В окончившейся партии получено %q", line)
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	d := newProtocolDriver(t, modeClassic)
+	for _, cmd := range []string{
+		"position startpos moves 0,0 0,0",
+		"position fen x",
+		"go depth",
+		"go nodes 5",
+		"newgame chess",
+		"newgame " + modePuzzle,
+	} {
+		d.send(cmd)
+		if line := d.read(); !strings.HasPrefix(line, "info string error: ") {
+			t.Errorf("%q: ожидалась ошибка, получено %q", cmd, line)
+		}
+	}
+	d.send("hello")
+	if line := d.read(); line != `info string unknown command "hello"` {
+		t.Errorf("Неизвестная команда: %q", line)
+	}
+
+	// После неверной позиции остаётся прежняя.
+	d.send("position startpos moves 0,0")
+	d.send("position startpos moves 1,1 1,1")
+	d.read()
+	d.send("go depth 1")
+	if line, _ := d.expect("bestmove "); line == "bestmove 0,0" {
+		t.Errorf("Ход в занятую клетку: %q", line)
+	}
+}
+
+func TestProtocolStop(t *testing.T) {
+	d := newProtocolDriver(t, modeUltimate)
+	d.send("go depth 100 infinite")
+	if line := d.read(); !infoLine.MatchString(line) {
+		t.Fatalf("Неверная строка info %q", line)
+	}
+	d.send("stop")
+	if line, _ := d.expect("bestmove "); line == "bestmove (none)" {
+		t.Errorf("После stop нет хода: %q", line)
+	}
+	d.send("isready")
+	if line, _ := d.expect("readyok"); line != "readyok" {
+		t.Errorf("Ожидалось readyok, получено %q", line)
+	}
+}
+
+func TestProtocolEndOfInput(t *testing.T) {
+	// Конец ввода прерывает бесконечный поиск, как quit.
+	d := newProtocolDriver(t, modeUltimate)
+	d.send("go depth 100 infinite")
+	d.read()
+	d.in.Close()
+	if line, _ := d.expect("bestmove "); line == "bestmove (none)" {
+		t.Errorf("После конца ввода нет хода: %q", line)
+	}
+	select {
+	case err := <-d.done:
+		if err != nil {
+			t.Errorf("Ошибка после конца ввода: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Движок не завершился по концу ввода")
+	}
+}
+
+func TestProtocolInfinite(t *testing.T) {
+	// Без depth бесконечный поиск идёт глубже глубины варианта, пока его не остановят.
+	d := newProtocolDriver(t, modeGravity)
+	d.send("go infinite")
+	want := fmt.Sprintf("info depth %d ", gravityDepth+1)
+	for {
+		line := d.read()
+		if strings.HasPrefix(line, "bestmove ") {
+			t.Fatalf("Поиск закончился без stop: %q", line)
+		}
+		if strings.HasPrefix(line, want) {
+			break
+		}
+	}
+	d.send("stop")
+	d.expect("bestmove ")
+
+	// Законченный поиск infinite всё равно ждёт stop с ответом.
+	d = newProtocolDriver(t, modeClassic)
+	d.send("go infinite")
+	if line, _ := d.expect("info depth "); !infoLine.MatchString(line) {
+		t.Fatalf("Неверная строка info %q", line)
+	}
+	// Полный перебор поля 3×3 заканчивается много быстрее полсекунды.
+	wait := time.After(500 * time.Millisecond)
+	for waiting := true; waiting; {
+		select {
+		case line := <-d.lines:
+			if strings.HasPrefix(line, "bestmove ") {
+				t.Fatalf("Ответ до stop: %q", line)
+			}
+		case <-wait:
+			waiting = false
+		}
+	}
+	d.send("stop")
+	if line, _ := d.expect("bestmove "); line == "bestmove (none)" {
+		t.Errorf("После stop нет хода: %q", line)
+	}
+}
+
+func TestProtocolEngineFlag(t *testing.T) {
+	// Ход выбирает движок из -engine; движки без перебора не выводят info.
+	d := newEngineDriver(t, modeClassic, "random")
+	d.send("position startpos moves 1,1")
+	d.send("go movetime 100")
+	line, infos := d.expect("bestmove ")
+	r := variants[modeClassic].newRules()
+	r = r.Apply(r.LegalMoves()[4])
+	if _, err := parseMove(r, strings.TrimPrefix(line, "bestmove ")); err != nil || len(infos) != 0 {
+		t.Errorf("Движок random ответил %q после %q", line, infos)
+	}
+
+	d = newEngineDriver(t, modeClassic, "minimax:2")
+	d.send("go")
+	if _, infos := d.expect("bestmove "); len(infos) != 2 || !strings.HasPrefix(infos[1], "info depth 2 ") {
+		t.Errorf("minimax:2 вывел %q", infos)
+	}
+
+	if err := runProtocol(strings.NewReader(""), io.Discard, modeGravity, "q", "hard"); err == nil {
+		t.Error("Агент Q-обучения принят в варианте gravity")
+	}
+}
+
+func TestProtocolTimeLimits(t *testing.T) {
+	d := newProtocolDriver(t, modeClassic)
+	d.send("newgame " + modeUltimate)
+	for _, cmd := range []string{"go depth 100 movetime 50", "go depth 100 xtime 1000 otime 1000"} {
+		start := time.Now()
+		d.send(cmd)
+		if line, _ := d.expect("bestmove "); line == "bestmove (none)" {
+			t.Errorf("%q: нет хода", cmd)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("%q: поиск шёл %s", cmd, elapsed)
+		}
+	}
+}
+
+func TestGoLimitsBudget(t *testing.T) {
+	l, err := parseGoLimits([]string{"depth", "4", "movetime", "500", "xtime", "2000", "xinc", "100"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Depth != 4 || l.MoveTime != 500*time.Millisecond {
+		t.Errorf("Неверные ограничения %+v", l)
+	}
+	// У крестиков доля часов меньше movetime: 2000/20+100 минус запас.
+	if b := l.budget(Cross); b < 150*time.Millisecond || b > 200*time.Millisecond {
+		t.Errorf("Время крестиков %s", b)
+	}
+	if b := l.budget(Circle); b != 500*time.Millisecond {
+		t.Errorf("Время ноликов %s, ожидалось movetime", b)
+	}
+	if l, _ := parseGoLimits([]string{"movetime", "500", "infinite"}); l.budget(Cross) != 0 {
+		t.Error("infinite должен снимать ограничение времени")
+	}
+}
+
+func TestFormatScore(t *testing.T) {
+	cases := []struct {
+		val, depth int
+		want       string
+	}{
+		{0, 5, "0"},
+		{-12, 5, "-12"},
+		{winScore + 4, 5, "win 1"},
+		{winScore + 2, 5, "win 3"},
+		{-winScore - 3, 5, "loss 2"},
+	}
+	for _, c := range cases {
+		if got := formatScore(c.val, c.depth); got != c.want {
+			t.Errorf("formatScore(%d, %d) = %q, ожидалось %q", c.val, c.depth, got, c.want)
+		}
+	}
+}
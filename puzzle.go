@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+)
+
+const (
+	// puzzleCount — число задач, создаваемых для сеанса, если набор не загружен из файла.
+	puzzleCount = 50
+	// puzzleMaxMoves — наибольшее число ходов до выигрыша в задачах для полей больше 3×3:
+	// проверка ответа перебором на глубину 2N-1 должна оставаться быстрой.
+	puzzleMaxMoves = 3
+)
+
+// Puzzle — задача «ходят X и выигрывают в N ходов» на классическом поле.
+// Строки поля записываются символами X, O и точкой для пустой клетки.
+type Puzzle struct {
+	Size   int      `json:"size"`
+	K      int      `json:"k"`
+	Misere bool     `json:"misere,omitempty"`
+	Rows   []string `json:"rows"`
+	ToMove string   `json:"to_move"`
+	WinIn  int      `json:"win_in"`
+}
+
+// Title возвращает условие задачи.
+func (p Puzzle) Title() string {
+	return fmt.Sprintf("%s to move and win in %d", p.ToMove, p.WinIn)
+}
+
+// position строит позицию задачи: в битовом представлении, если поле помещается в маску.
+func (p Puzzle) position() (Rules, error) {
+	if p.Size < 3 || p.K < 3 || p.K > p.Size || len(p.Rows) != p.Size {
+		return nil, fmt.Errorf("неверное поле %d×%d с линией %d", p.Size, p.Size, p.K)
+	}
+	if p.WinIn < 1 {
+		return nil, fmt.Errorf("неверное число ходов %d", p.WinIn)
+	}
+	r := newClassicRules(p.Size, p.K, p.Misere)
+	for i, row := range p.Rows {
+		if len(row) != p.Size {
+			return nil, fmt.Errorf("строка %q не из %d клеток", row, p.Size)
+		}
+		for j, ch := range row {
+			switch ch {
+			case 'X':
+				r.Cells[i*p.Size+j] = Cross
+			case 'O':
+				r.Cells[i*p.Size+j] = Circle
+			case '.':
+			default:
+				return nil, fmt.Errorf("неизвестная фишка %q", ch)
+			}
+		}
+	}
+	switch p.ToMove {
+	case symbolName(Cross):
+		r.Turn = Cross
+	case symbolName(Circle):
+		r.Turn = Circle
+	default:
+		return nil, fmt.Errorf("неизвестный ходящий %q", p.ToMove)
+	}
+	if r.Terminal() {
+		return nil, fmt.Errorf("партия в задаче уже окончена")
+	}
+	if p.Size*p.Size <= maxBitboardCells {
+		return bitRulesFromClassic(r), nil
+	}
+	return r, nil
+}
+
+// puzzleFrom записывает позицию классического поля задачей с выигрышем в n ходов.
+func puzzleFrom(r Rules, size, k int, misere bool, n int) Puzzle {
+	return Puzzle{
+		Size:   size,
+		K:      k,
+		Misere: misere,
+		Rows:   strings.Split(strings.TrimSuffix(renderText(r), "\n"), "\n"),
+		ToMove: symbolName(r.ToMove()),
+		WinIn:  n,
+	}
+}
+
+// keepsWin проверяет ответ перебором minimax: после хода m ходящий в позиции r
+// по-прежнему выигрывает не более чем за n своих ходов, считая m.
+func keepsWin(r Rules, m Move, n int) bool {
+	next := r.Apply(m)
+	if next.Terminal() {
+		return next.Winner() == r.ToMove()
+	}
+	if n <= 1 {
+		return false
+	}
+	return -minimax(next, 2*n-2, -inf, inf) >= winScore
+}
+
+// solveTree решает все позиции, достижимые из r, и дописывает их в order в порядке обхода.
+// Оценка — для ходящего: winScore минус число полуходов до выигрыша, со знаком минус
+// для проигрыша, 0 — ничья. Годится только для маленьких полей, где дерево партии невелико.
+func solveTree(r Rules, memo map[string]int, order *[]Rules) int {
+	key := renderText(r) + symbolName(r.ToMove())
+	if v, ok := memo[key]; ok {
+		return v
+	}
+	v := 0
+	if r.Terminal() {
+		switch r.Winner() {
+		case r.ToMove():
+			v = winScore
+		case other(r.ToMove()):
+			v = -winScore
+		}
+	} else {
+		v = -inf
+		for _, m := range r.LegalMoves() {
+			child := -solveTree(r.Apply(m), memo, order)
+			if child > 0 {
+				child--
+			} else if child < 0 {
+				child++
+			}
+			v = max(v, child)
+		}
+	}
+	memo[key] = v
+	*order = append(*order, r)
+	return v
+}
+
+// generatePuzzles создаёт до count задач на поле size×size с линией k. Поле 3×3 решается
+// целиком, и задачами становятся все позиции с форсированным выигрышем; на больших полях
+// задачи ищутся в случайных партиях поиском выигрыша угрозами и проверяются перебором.
+func generatePuzzles(size, k int, misere bool, count int, rnd *rand.Rand) []Puzzle {
+	var puzzles []Puzzle
+	if size == boardSize {
+		memo := make(map[string]int)
+		var order []Rules
+		solveTree(newClassic(size, k, misere), memo, &order)
+		for _, r := range order {
+			v := memo[renderText(r)+symbolName(r.ToMove())]
+			if r.Terminal() || v <= 0 {
+				continue
+			}
+			plies := winScore - v
+			puzzles = append(puzzles, puzzleFrom(r, size, k, misere, (plies+1)/2))
+		}
+	} else if !misere {
+		for games := 0; games < count*20 && len(puzzles) < count; games++ {
+			for r := newClassic(size, k, false); !r.Terminal(); {
+				fw, ok := r.(forcedWinner)
+				if !ok {
+					break
+				}
+				if seq, _, found := fw.ForcedWin(context.Background()); found && len(seq) <= 2*puzzleMaxMoves-1 {
+					n := (len(seq) + 1) / 2
+					if keepsWin(r, seq[0], n) {
+						puzzles = append(puzzles, puzzleFrom(r, size, k, false, n))
+					}
+					break
+				}
+				moves := r.LegalMoves()
+				r = r.Apply(moves[rnd.Intn(len(moves))])
+			}
+		}
+	}
+	rnd.Shuffle(len(puzzles), func(i, j int) { puzzles[i], puzzles[j] = puzzles[j], puzzles[i] })
+	if len(puzzles) > count {
+		puzzles = puzzles[:count]
+	}
+	return puzzles
+}
+
+// loadPuzzles читает набор задач из JSON-файла и проверяет каждую позицию.
+func loadPuzzles(path string) ([]Puzzle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var puzzles []Puzzle
+	if err := json.Unmarshal(data, &puzzles); err != nil {
+		return nil, fmt.Errorf("чтение задач %s: %w", path, err)
+	}
+	if len(puzzles) == 0 {
+		return nil, fmt.Errorf("в файле %s нет задач", path)
+	}
+	for i, p := range puzzles {
+		if _, err := p.position(); err != nil {
+			return nil, fmt.Errorf("задача %d: %w", i+1, err)
+		}
+	}
+	return puzzles, nil
+}
+
+// puzzleSession — задачи текущего сеанса и серия решённых подряд.
+type puzzleSession struct {
+	Puzzles []Puzzle
+	Index   int // Текущая задача.
+	Left    int // Ходов до выигрыша в текущей задаче.
+	Streak  int // Задачи, решённые подряд.
+	Best    int // Самая длинная серия сеанса.
+	Solved  int
+	Failed  int
+}
+
+// newPuzzleSession начинает сеанс с первой задачи набора.
+func newPuzzleSession(puzzles []Puzzle) *puzzleSession {
+	return &puzzleSession{Puzzles: puzzles}
+}
+
+// Current возвращает текущую задачу.
+func (s *puzzleSession) Current() Puzzle {
+	return s.Puzzles[s.Index]
+}
+
+// start возвращает позицию текущей задачи, начиная её заново.
+func (s *puzzleSession) start() Rules {
+	p := s.Current()
+	s.Left = p.WinIn
+	r, err := p.position()
+	if err != nil {
+		panic(err) // Задачи проверяются при создании набора.
+	}
+	return r
+}
+
+// answer проверяет ход решающего m в позиции r. Неверный ход прерывает серию
+// и переводит сеанс к следующей задаче.
+func (s *puzzleSession) answer(r Rules, m Move) bool {
+	if keepsWin(r, m, s.Left) {
+		s.Left--
+		return true
+	}
+	s.fail()
+	return false
+}
+
+// fail засчитывает нерешённую задачу: серия прерывается, сеанс переходит к следующей.
+func (s *puzzleSession) fail() {
+	s.Streak = 0
+	s.Failed++
+	s.next()
+}
+
+// solve засчитывает решённую задачу и переводит сеанс к следующей.
+func (s *puzzleSession) solve() {
+	s.Streak++
+	s.Best = max(s.Best, s.Streak)
+	s.Solved++
+	s.next()
+}
+
+func (s *puzzleSession) next() {
+	s.Index = (s.Index + 1) % len(s.Puzzles)
+}
+
+// Status возвращает строку состояния сеанса.
+func (s *puzzleSession) Status() string {
+	return fmt.Sprintf("streak %d, best %d, solved %d/%d", s.Streak, s.Best, s.Solved, s.Solved+s.Failed)
+}
+
+// puzzles — сеанс задач режима puzzle; puzzleFile — файл набора задач,
+// пустая строка означает задачи, созданные для поля -size и -k.
+var (
+	puzzles    *puzzleSession
+	puzzleFile string
+)
+
+// sharedPuzzles возвращает сеанс задач, создавая его при первом обращении.
+func sharedPuzzles() (*puzzleSession, error) {
+	if puzzles != nil {
+		return puzzles, nil
+	}
+	var set []Puzzle
+	if puzzleFile != "" {
+		var err error
+		if set, err = loadPuzzles(puzzleFile); err != nil {
+			return nil, err
+		}
+	} else {
+		set = generatePuzzles(classicSize, classicK, misere, puzzleCount, gameRand)
+		if len(set) == 0 {
+			return nil, fmt.Errorf("для поля %d×%d с линией %d задач не нашлось", classicSize, classicSize, classicK)
+		}
+	}
+	puzzles = newPuzzleSession(set)
+	return puzzles, nil
+}
+
+// puzzlePosition начинает текущую задачу сеанса; конструктор варианта puzzle.
+func puzzlePosition() Rules {
+	s, err := sharedPuzzles()
+	if err != nil {
+		panic(err) // main проверяет набор задач до начала игры.
+	}
+	return s.start()
+}
+
+// runPuzzles реализует подкоманду "puzzle": задачи решаются в текстовом режиме,
+// компьютер защищается движком e. Сеанс идёт, пока не кончится ввод.
+func runPuzzles(in io.Reader, out io.Writer, s *puzzleSession, e Engine) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		p := s.Current()
+		r := s.start()
+		fmt.Fprintf(out, "puzzle %d: %s\n", s.Index+1, p.Title())
+		for {
+			fmt.Fprint(out, renderText(r))
+			fmt.Fprint(out, "your move: ")
+			if !scanner.Scan() {
+				fmt.Fprintf(out, "\n%s\n", s.Status())
+				return scanner.Err()
+			}
+			m, err := parseMove(r, scanner.Text())
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			if !s.answer(r, m) {
+				best, _, _ := bestMove(r, 2*s.Left-1)
+				fmt.Fprintf(out, "wrong, %s wins (%s)\n", formatMove(r, best), s.Status())
+				break
+			}
+			r = r.Apply(m)
+			if r.Terminal() {
+				s.solve()
+				fmt.Fprintf(out, "solved (%s)\n", s.Status())
+				break
+			}
+			reply, _, ok := e.BestMove(context.Background(), r)
+			if !ok {
+				return fmt.Errorf("компьютер не нашёл ответа")
+			}
+			fmt.Fprintf(out, "computer: %s\n", formatMove(r, reply))
+			r = r.Apply(reply)
+		}
+	}
+}
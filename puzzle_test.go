@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGeneratePuzzles(t *testing.T) {
+	set := generatePuzzles(boardSize, winLength, false, 30, rand.New(rand.NewSource(1)))
+	if len(set) != 30 {
+		t.Fatalf("Создано %d задач вместо 30", len(set))
+	}
+	for _, p := range set {
+		r, err := p.position()
+		if err != nil {
+			t.Fatalf("%v: %v", p.Rows, err)
+		}
+		// Выигрыш за WinIn ходов есть, а быстрее — нет.
+		wins, faster := false, false
+		for _, m := range r.LegalMoves() {
+			wins = wins || keepsWin(r, m, p.WinIn)
+			faster = faster || p.WinIn > 1 && keepsWin(r, m, p.WinIn-1)
+		}
+		if !wins || faster {
+			t.Errorf("%v: %s не подтверждается перебором", p.Rows, p.Title())
+		}
+	}
+}
+
+func TestGeneratePuzzlesLargerBoard(t *testing.T) {
+	set := generatePuzzles(4, 3, false, 5, rand.New(rand.NewSource(1)))
+	if len(set) == 0 {
+		t.Fatal("На поле 4×4 не создано задач")
+	}
+	for _, p := range set {
+		r, err := p.position()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, _, _ := bestMove(r, 2*p.WinIn-1)
+		if p.WinIn > puzzleMaxMoves || !keepsWin(r, m, p.WinIn) {
+			t.Errorf("%v: %s не подтверждается перебором", p.Rows, p.Title())
+		}
+	}
+}
+
+func TestKeepsWin(t *testing.T) {
+	win := bitRulesFromClassic(classicFromText(3, Cross, "XX.", "OO.", "..."))
+	if !keepsWin(win, Move{Row: 0, Col: 2}, 1) || keepsWin(win, Move{Row: 1, Col: 2}, 2) {
+		t.Errorf("Неверная проверка выигрыша в один ход")
+	}
+	// Центр выигрывает за три хода: угроза, затем вилка.
+	r := bitRulesFromClassic(classicFromText(3, Cross, "XO.", "...", "..."))
+	center := Move{Row: 1, Col: 1}
+	if keepsWin(r, center, 2) || !keepsWin(r, center, 3) {
+		t.Errorf("Неверная проверка выигрыша в три хода")
+	}
+}
+
+func TestLoadPuzzles(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	os.WriteFile(good, []byte(`[{"size":3,"k":3,"rows":["XX.","OO.","..."],"to_move":"X","win_in":1}]`), 0o644)
+	set, err := loadPuzzles(good)
+	if err != nil || len(set) != 1 || set[0].Title() != "X to move and win in 1" {
+		t.Fatalf("Набор не загружен: %v, %v", set, err)
+	}
+
+	for name, data := range map[string]string{
+		"empty":   `[]`,
+		"rows":    `[{"size":3,"k":3,"rows":["XX.","OO."],"to_move":"X","win_in":1}]`,
+		"symbol":  `[{"size":3,"k":3,"rows":["XX.","OZ.","..."],"to_move":"X","win_in":1}]`,
+		"turn":    `[{"size":3,"k":3,"rows":["XX.","OO.","..."],"to_move":"Y","win_in":1}]`,
+		"over":    `[{"size":3,"k":3,"rows":["XXX","OO.","..."],"to_move":"O","win_in":1}]`,
+		"invalid": `{`,
+	} {
+		path := filepath.Join(dir, name+".json")
+		os.WriteFile(path, []byte(data), 0o644)
+		if _, err := loadPuzzles(path); err == nil {
+			t.Errorf("%s: ожидалась ошибка", name)
+		}
+	}
+	if _, err := loadPuzzles(filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("Ожидалась ошибка для отсутствующего файла")
+	}
+}
+
+func TestRunPuzzles(t *testing.T) {
+	// Первый ответ неверен и прерывает серию, второй решает ту же задачу заново.
+	s := newPuzzleSession([]Puzzle{{Size: 3, K: 3, Rows: []string{"XX.", "OO.", "..."}, ToMove: "X", WinIn: 1}})
+	var out bytes.Buffer
+	if err := runPuzzles(strings.NewReader("2,2\n9,9\n0,2\n"), &out, s, minimaxEngine{Depth: 9}); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+	for _, want := range []string{"X to move and win in 1", "wrong, 0,2 wins", "недопустимый ход", "solved (streak 1, best 1, solved 1/2)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Нет %q в выводе:\n%s", want, text)
+		}
+	}
+}
+
+func TestPuzzleMode(t *testing.T) {
+	// В окне решающий ходит сам, компьютер защищается, решённая задача продлевает серию.
+	var set []Puzzle
+	for _, p := range generatePuzzles(boardSize, winLength, false, 1000, rand.New(rand.NewSource(1))) {
+		if p.WinIn == 2 && len(set) < 2 {
+			set = append(set, p)
+		}
+	}
+	oldMode, oldPuzzles := gameMode, puzzles
+	gameMode, puzzles = modePuzzle, newPuzzleSession(set)
+	defer func() {
+		gameMode, puzzles = oldMode, oldPuzzles
+		resetGame()
+	}()
+
+	resetGame()
+	if aiSide == game.ToMove() || puzzleTitle != set[0].Title() {
+		t.Fatalf("Задача начата неверно: компьютер %v, условие %q", aiSide, puzzleTitle)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for !gameOver {
+		if time.Now().After(deadline) {
+			t.Fatal("Задача не решена")
+		}
+		if game.ToMove() != aiSide {
+			m, _, _ := bestMove(game, 2*puzzles.Left-1)
+			playMove(m)
+			continue
+		}
+		updateSearch()
+		time.Sleep(time.Millisecond)
+	}
+	if winnerString != "Solved!" || puzzles.Streak != 1 || puzzles.Index != 1 {
+		t.Fatalf("Решение не засчитано: %q, серия %d", winnerString, puzzles.Streak)
+	}
+
+	// Ход в задаче не отменяется.
+	resetGame()
+	left := puzzles.Left
+	m, _, _ := bestMove(game, 2*left-1)
+	playMove(m)
+	undoMove()
+	if len(history) != 1 || puzzles.Left != left-1 {
+		t.Errorf("Ход в задаче отменён: %d ходов, осталось %d", len(history), puzzles.Left)
+	}
+
+	// Ход, упускающий выигрыш, заканчивает задачу и прерывает серию.
+	resetGame()
+	for _, m := range game.LegalMoves() {
+		if !keepsWin(game, m, puzzles.Left) {
+			playMove(m)
+			break
+		}
+	}
+	if !gameOver || !strings.HasPrefix(winnerString, "Wrong!") || puzzles.Streak != 0 || puzzles.Best != 1 {
+		t.Errorf("Ошибка не засчитана: %q, серия %d", winnerString, puzzles.Streak)
+	}
+	if len(history) != 0 {
+		t.Errorf("Неверный ход сделан на поле")
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+const (
+	qubicSize  = 4
+	qubicDepth = 3
+)
+
+// QubicBoard хранит состояние игры 4×4×4 (Qubic).
+type QubicBoard struct {
+	Cells [qubicSize][qubicSize][qubicSize]Player
+	Turn  Player
+}
+
+// qubicLines содержит все 76 выигрышных линий куба 4×4×4.
+var qubicLines = generateQubicLines(qubicSize)
+
+// newQubicBoard создаёт пустую партию, в которой первыми ходят крестики.
+func newQubicBoard() QubicBoard {
+	return QubicBoard{Turn: Cross}
+}
+
+// generateQubicLines перечисляет все прямые длины n в кубе n×n×n:
+// строки, столбцы, вертикали, диагонали плоскостей и четыре главные диагонали куба.
+func generateQubicLines(n int) [][]Cell3 {
+	var lines [][]Cell3
+	for dl := -1; dl <= 1; dl++ {
+		for dr := -1; dr <= 1; dr++ {
+			for dc := -1; dc <= 1; dc++ {
+				// Берём только одно направление из каждой пары противоположных.
+				if dl < 0 || (dl == 0 && dr < 0) || (dl == 0 && dr == 0 && dc <= 0) {
+					continue
+				}
+				for l := 0; l < n; l++ {
+					for r := 0; r < n; r++ {
+						for c := 0; c < n; c++ {
+							endL, endR, endC := l+dl*(n-1), r+dr*(n-1), c+dc*(n-1)
+							if endL < 0 || endL >= n || endR < 0 || endR >= n || endC < 0 || endC >= n {
+								continue
+							}
+							line := make([]Cell3, n)
+							for k := 0; k < n; k++ {
+								line[k] = Cell3{l + dl*k, r + dr*k, c + dc*k}
+							}
+							lines = append(lines, line)
+						}
+					}
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// at возвращает фишку в клетке c.
+func (q *QubicBoard) at(c Cell3) Player {
+	return q.Cells[c.Layer][c.Row][c.Col]
+}
+
+// line возвращает победителя и его линию или Empty, если линии нет.
+func (q *QubicBoard) line() (Player, []Cell3) {
+	for _, line := range qubicLines {
+		p := q.at(line[0])
+		if p == Empty {
+			continue
+		}
+		full := true
+		for _, c := range line[1:] {
+			if q.at(c) != p {
+				full = false
+				break
+			}
+		}
+		if full {
+			return p, line
+		}
+	}
+	return Empty, nil
+}
+
+// legalMoves возвращает все свободные клетки, если партия не окончена.
+func (q *QubicBoard) legalMoves() []Cell3 {
+	var moves []Cell3
+	if w, _ := q.line(); w != Empty {
+		return moves
+	}
+	for l := 0; l < qubicSize; l++ {
+		for r := 0; r < qubicSize; r++ {
+			for c := 0; c < qubicSize; c++ {
+				if q.Cells[l][r][c] == Empty {
+					moves = append(moves, Cell3{l, r, c})
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// play возвращает позицию после хода в клетку c.
+func (q QubicBoard) play(c Cell3) QubicBoard {
+	q.Cells[c.Layer][c.Row][c.Col] = q.Turn
+	q.Turn = other(q.Turn)
+	return q
+}
+
+// qubicLineWeights задаёт ценность открытой линии в зависимости от числа фишек в ней.
+var qubicLineWeights = [qubicSize]int{0, 1, 10, 100}
+
+// qubicHeuristic оценивает позицию с точки зрения ноликов: каждая линия,
+// в которой есть фишки только одного игрока, приносит ему очки по числу фишек.
+func qubicHeuristic(q *QubicBoard) int {
+	score := 0
+	for _, line := range qubicLines {
+		circles, crosses := 0, 0
+		for _, c := range line {
+			switch q.at(c) {
+			case Circle:
+				circles++
+			case Cross:
+				crosses++
+			}
+		}
+		if crosses == 0 {
+			score += qubicLineWeights[circles]
+		} else if circles == 0 {
+			score -= qubicLineWeights[crosses]
+		}
+	}
+	return score
+}
+
+func (q *QubicBoard) Name() string {
+	return modeQubic
+}
+
+func (q *QubicBoard) ToMove() Player {
+	return q.Turn
+}
+
+func (q *QubicBoard) At(c Cell3) Player {
+	return q.at(c)
+}
+
+func (q *QubicBoard) Winner() Player {
+	w, _ := q.line()
+	return w
+}
+
+func (q *QubicBoard) LegalMoves() []Move {
+	var moves []Move
+	for _, c := range q.legalMoves() {
+		moves = append(moves, Move{Layer: c.Layer, Row: c.Row, Col: c.Col, Symbol: q.Turn})
+	}
+	return moves
+}
+
+func (q *QubicBoard) Apply(m Move) Rules {
+	next := q.play(m.To())
+	return &next
+}
+
+func (q *QubicBoard) Terminal() bool {
+	return len(q.legalMoves()) == 0
+}
+
+func (q *QubicBoard) Evaluate() int {
+	if q.Turn == Cross {
+		return -qubicHeuristic(q)
+	}
+	return qubicHeuristic(q)
+}
+
+func (q *QubicBoard) Hints() RenderHints {
+	_, line := q.line()
+	return RenderHints{Layers: qubicSize, Rows: qubicSize, Cols: qubicSize, Line: line}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestQubicLines(t *testing.T) {
+	if len(qubicLines) != 76 {
+		t.Fatalf("Ожидалось 76 линий, но получено %d", len(qubicLines))
+	}
+
+	// Каждая линия уникальна и состоит из четырёх клеток на одной прямой.
+	seen := make(map[[2]Cell3]bool)
+	for _, line := range qubicLines {
+		if len(line) != qubicSize {
+			t.Fatalf("Линия %v должна содержать %d клетки", line, qubicSize)
+		}
+		key := [2]Cell3{line[0], line[qubicSize-1]}
+		if seen[key] {
+			t.Errorf("Линия %v повторяется", line)
+		}
+		seen[key] = true
+	}
+
+	// Главная диагональ куба входит в число линий.
+	if !seen[[2]Cell3{{0, 0, 0}, {3, 3, 3}}] {
+		t.Errorf("Нет главной диагонали куба")
+	}
+}
+
+func TestQubicWinner(t *testing.T) {
+	q := newQubicBoard()
+	for k := 0; k < qubicSize; k++ {
+		q.Cells[k][k][qubicSize-1-k] = Circle
+	}
+	w, line := q.line()
+	if w != Circle || len(line) != qubicSize {
+		t.Errorf("Ожидалась победа ноликов по диагонали, но получено %v %v", w, line)
+	}
+	if moves := q.legalMoves(); len(moves) != 0 {
+		t.Errorf("После победы не должно быть ходов, но получено %d", len(moves))
+	}
+}
+
+func TestQubicBestMove(t *testing.T) {
+	// Нолики завершают вертикаль сквозь слои.
+	q := newQubicBoard()
+	q.Turn = Circle
+	for l := 0; l < 3; l++ {
+		q.Cells[l][1][2] = Circle
+	}
+	q.Cells[0][0][0] = Cross
+	q.Cells[1][0][0] = Cross
+	q.Cells[2][0][0] = Cross
+
+	m, _, ok := bestMove(&q, qubicDepth)
+	expected := Cell3{3, 1, 2}
+	if c := m.To(); !ok || c != expected {
+		t.Errorf("Ожидался ход %v, но получен %v", expected, c)
+	}
+
+	// Крестики закрывают единственную угрозу ноликов.
+	q.Cells[2][0][0] = Empty
+	q.Turn = Cross
+	m, _, ok = bestMove(&q, qubicDepth)
+	if c := m.To(); !ok || c != expected {
+		t.Errorf("Ожидался ход %v, но получен %v", expected, c)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// gameRand — источник случайности игры: случайные ходы, обучение, задачи.
+// По умолчанию начальное значение постоянно, и поведение воспроизводимо.
+var gameRand = rand.New(rand.NewSource(1))
+
+// seedRandom задаёт начальное значение gameRand; 0 означает текущее время.
+// Возвращает выбранное значение, чтобы его можно было записать в журнал
+// и повторить сеанс флагом -seed.
+func seedRandom(seed int64) int64 {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	gameRand = rand.New(rand.NewSource(seed))
+	return seed
+}
+
+// lockedSource — источник случайных чисел, который можно использовать из нескольких горутин.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+// newEngineRand создаёт генератор движка с начальным значением из gameRand. Генератор
+// у каждого движка свой, чтобы ходы зависели только от начального значения gameRand.
+// Прерванный фоновый поиск может ещё идти, когда тот же движок начинает следующий,
+// поэтому источник защищён мьютексом.
+func newEngineRand() *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(gameRand.Int63())})
+}
+
+// randomEngine ходит случайно из допустимых ходов.
+type randomEngine struct {
+	Rand *rand.Rand
+}
+
+// newRandomEngine создаёт движок с генератором из newEngineRand.
+func newRandomEngine() *randomEngine {
+	return &randomEngine{Rand: newEngineRand()}
+}
+
+func (e *randomEngine) Name() string {
+	return "random"
+}
+
+func (e *randomEngine) BestMove(ctx context.Context, r Rules) (Move, SearchInfo, bool) {
+	moves := r.LegalMoves()
+	if len(moves) == 0 {
+		return Move{}, SearchInfo{}, false
+	}
+	return moves[e.Rand.Intn(len(moves))], SearchInfo{Nodes: len(moves)}, true
+}
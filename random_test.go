@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+var testSeed = flag.Int64("seed", 0, "начальное значение случайных тестов; 0 — текущее время")
+
+// testRand возвращает генератор для случайного теста и записывает начальное значение
+// в журнал теста: упавший тест повторяется запуском go test -args -seed=N.
+func testRand(t testing.TB) *rand.Rand {
+	t.Helper()
+	seed := *testSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t.Logf("seed %d", seed)
+	return rand.New(rand.NewSource(seed))
+}
+
+func TestSeedRandom(t *testing.T) {
+	defer seedRandom(1)
+	if seed := seedRandom(42); seed != 42 {
+		t.Errorf("seedRandom(42) = %d", seed)
+	}
+	first := gameRand.Int63()
+	seedRandom(42)
+	if gameRand.Int63() != first {
+		t.Errorf("Одно начальное значение дало разные последовательности")
+	}
+	if seedRandom(0) == 0 {
+		t.Errorf("Начальное значение не выбрано")
+	}
+}
+
+func TestRandomEngine(t *testing.T) {
+	// Движки с одним начальным значением играют одинаково.
+	defer seedRandom(1)
+	play := func() []Move {
+		seedRandom(7)
+		e := newRandomEngine()
+		var moves []Move
+		for r := Rules(newBitRules(boardSize, winLength, false)); !r.Terminal(); {
+			m, _, ok := e.BestMove(context.Background(), r)
+			if !ok {
+				t.Fatal("Нет хода в неоконченной партии")
+			}
+			moves = append(moves, m)
+			r = r.Apply(m)
+		}
+		return moves
+	}
+	a, b := play(), play()
+	if len(a) != len(b) {
+		t.Fatalf("Партии разной длины: %v и %v", a, b)
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Партии расходятся на ходе %d: %v и %v", i, a, b)
+		}
+	}
+
+	final := newBitRules(boardSize, winLength, false)
+	final.Bits[bitIndex(Cross)] = final.geo.full
+	if _, _, ok := newRandomEngine().BestMove(context.Background(), final); ok {
+		t.Errorf("Ход найден в оконченной партии")
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Cell3 задаёт клетку поля с учётом слоя. У плоских вариантов слой всегда 0.
+type Cell3 struct {
+	Layer, Row, Col int
+}
+
+// Move — ход в любом варианте игры.
+type Move struct {
+	Layer, Row, Col int
+	From            Cell   // Клетка, из которой переносится фишка, если Shift.
+	Shift           bool   // Ход переносом уже стоящей фишки, а не выставлением новой.
+	Symbol          Player // Ставимый символ. Во всех вариантах, кроме wild, — фишка ходящего.
+}
+
+// To возвращает клетку, в которую делается ход.
+func (m Move) To() Cell3 {
+	return Cell3{m.Layer, m.Row, m.Col}
+}
+
+// RenderHints описывает, как показывать позицию и принимать ходы в интерфейсе.
+type RenderHints struct {
+	Layers, Rows, Cols int
+	Block              int              // Размер малых полей, разделяемых толстыми линиями; 0 — без разбиения.
+	Active             []Cell3          // Клетки, куда сейчас можно ходить, если их нужно подсветить.
+	Marked             map[Cell3]Player // Клетки, закрашиваемые в цвет владельца.
+	Line               []Cell3          // Выигрышная линия.
+	Drop               bool             // Ход задаётся столбцом, фишка падает вниз.
+	Shift              bool             // Ходы делаются переносом своей фишки.
+	Symbols            bool             // Игрок сам выбирает ставимый символ.
+	Note               string           // Пояснение к результату партии.
+}
+
+// Rules — правила варианта игры вместе с текущей позицией.
+// Позиции неизменяемы: Apply возвращает новую позицию, не меняя исходную.
+type Rules interface {
+	// Name возвращает название варианта с его параметрами для статистики и турниров.
+	Name() string
+	// ToMove возвращает игрока, который сейчас ходит.
+	ToMove() Player
+	// At возвращает фишку в клетке.
+	At(c Cell3) Player
+	// LegalMoves возвращает допустимые ходы; в окончившейся партии их нет.
+	LegalMoves() []Move
+	// Apply возвращает позицию после хода m.
+	Apply(m Move) Rules
+	// Terminal сообщает, окончена ли партия.
+	Terminal() bool
+	// Winner возвращает победителя окончившейся партии или Empty при ничьей.
+	Winner() Player
+	// Evaluate эвристически оценивает незавершённую позицию с точки зрения ходящего.
+	Evaluate() int
+	// Hints возвращает подсказки для отрисовки.
+	Hints() RenderHints
+}
+
+// variant описывает вариант игры: конструктор начальной позиции, глубину перебора
+// и сторону, за которую играет компьютер (Empty — игра двух людей).
+type variant struct {
+	newRules func() Rules
+	depth    int
+	aiSide   Player
+}
+
+// variants — все варианты, доступные через флаг -mode.
+var variants = map[string]variant{
+	modeClassic:  {newRules: func() Rules { return newClassic(classicSize, classicK, misere) }, depth: boardSize * boardSize, aiSide: Empty},
+	modeUltimate: {newRules: func() Rules { u := newUltimateBoard(); return &u }, depth: ultimateDepth, aiSide: Circle},
+	modeQubic:    {newRules: func() Rules { q := newQubicBoard(); return &q }, depth: qubicDepth, aiSide: Circle},
+	modeGravity:  {newRules: func() Rules { g := newGravityBoard(); return &g }, depth: gravityDepth, aiSide: Circle},
+	modeMorris:   {newRules: func() Rules { g := newMorrisGame(); return &g }, depth: 1, aiSide: Circle},
+	modeWild:     {newRules: func() Rules { w := newWildBoard(); return &w }, depth: 1, aiSide: Circle},
+	modePuzzle:   {newRules: puzzlePosition, depth: boardSize * boardSize, aiSide: Circle},
+}
+
+// variantNames возвращает названия вариантов в алфавитном порядке.
+func variantNames() []string {
+	names := make([]string, 0, len(variants))
+	for name := range variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// symbolName возвращает обозначение фишки в тексте.
+func symbolName(p Player) string {
+	switch p {
+	case Circle:
+		return "O"
+	case Cross:
+		return "X"
+	}
+	return "."
+}
+
+// formatCell записывает клетку как "строка,столбец" или "слой,строка,столбец" для многослойных полей.
+func formatCell(h RenderHints, c Cell3) string {
+	if h.Layers > 1 {
+		return fmt.Sprintf("%d,%d,%d", c.Layer, c.Row, c.Col)
+	}
+	return fmt.Sprintf("%d,%d", c.Row, c.Col)
+}
+
+// formatMove записывает ход текстом: "1,2", перенос "0,0>1,2", ход символом "O@1,2".
+func formatMove(r Rules, m Move) string {
+	h := r.Hints()
+	s := formatCell(h, m.To())
+	if m.Shift {
+		s = formatCell(h, Cell3{0, m.From.Row, m.From.Col}) + ">" + s
+	}
+	if h.Symbols {
+		s = symbolName(m.Symbol) + "@" + s
+	}
+	return s
+}
+
+// parseMove находит допустимый ход по его текстовой записи.
+// В вариантах с гравитацией достаточно указать номер столбца.
+func parseMove(r Rules, s string) (Move, error) {
+	s = strings.TrimSpace(s)
+	col, err := strconv.Atoi(s)
+	dropCol := err == nil && r.Hints().Drop
+
+	for _, m := range r.LegalMoves() {
+		if formatMove(r, m) == s || (dropCol && m.Col == col) {
+			return m, nil
+		}
+	}
+	return Move{}, fmt.Errorf("недопустимый ход %q", s)
+}
+
+// renderText рисует позицию текстом; слои располагаются рядом друг с другом.
+func renderText(r Rules) string {
+	h := r.Hints()
+	var b strings.Builder
+	for row := 0; row < h.Rows; row++ {
+		for layer := 0; layer < h.Layers; layer++ {
+			if layer > 0 {
+				b.WriteString("  ")
+			}
+			for col := 0; col < h.Cols; col++ {
+				b.WriteString(symbolName(r.At(Cell3{layer, row, col})))
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
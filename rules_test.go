@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVariantsPlayout(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, name := range variantNames() {
+		for game := 0; game < 5; game++ {
+			r := variants[name].newRules()
+			for plies := 0; ; plies++ {
+				moves := r.LegalMoves()
+				if (len(moves) == 0) != r.Terminal() {
+					t.Fatalf("%s: ходов %d, но Terminal() = %v", name, len(moves), r.Terminal())
+				}
+				if r.Terminal() {
+					break
+				}
+				if plies > 500 {
+					t.Fatalf("%s: партия не закончилась за %d полуходов", name, plies)
+				}
+
+				for _, m := range moves {
+					parsed, err := parseMove(r, formatMove(r, m))
+					if err != nil || parsed != m {
+						t.Fatalf("%s: ход %v после записи %q прочитан как %v (%v)", name, m, formatMove(r, m), parsed, err)
+					}
+				}
+
+				before := renderText(r)
+				next := r.Apply(moves[rnd.Intn(len(moves))])
+				if renderText(r) != before {
+					t.Fatalf("%s: Apply изменил исходную позицию", name)
+				}
+				if next.ToMove() == r.ToMove() {
+					t.Fatalf("%s: после хода очередь не перешла", name)
+				}
+				r = next
+			}
+		}
+	}
+}
+
+func TestBestMoveFindsWin(t *testing.T) {
+	// Крестики выигрывают ходом в (0, 2).
+	r := newClassicRules(boardSize, winLength, false)
+	for _, m := range []Move{{Row: 0, Col: 0}, {Row: 1, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}} {
+		r = r.Apply(m).(*ClassicRules)
+	}
+	m, score, ok := bestMove(r, 9)
+	if !ok || m.Row != 0 || m.Col != 2 || score <= winScore {
+		t.Errorf("Ожидался выигрыш ходом (0, 2), но получен %v с оценкой %d", m, score)
+	}
+	if w := r.Apply(m).Winner(); w != Cross {
+		t.Errorf("Ожидалась победа крестиков, но получено %v", w)
+	}
+}
+
+func TestRunPlay(t *testing.T) {
+	// Первый ввод недопустим; при полном переборе компьютер не проигрывает.
+	in := strings.NewReader("9,9\n1,1\n0,1\n2,0\n1,2\n2,2\n")
+	var out bytes.Buffer
+	if err := runPlay(in, &out, newClassicRules(boardSize, winLength, false), minimaxEngine{Depth: 9}); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+	if !strings.Contains(text, "недопустимый ход") {
+		t.Errorf("Нет сообщения о недопустимом ходе:\n%s", text)
+	}
+	if !strings.Contains(text, "computer: ") {
+		t.Errorf("Компьютер не сделал ни одного хода:\n%s", text)
+	}
+	if strings.Contains(text, "You win") {
+		t.Errorf("Компьютер проиграл при полном переборе:\n%s", text)
+	}
+}
+
+func TestRunPlayEOF(t *testing.T) {
+	var out bytes.Buffer
+	err := runPlay(strings.NewReader(""), &out, newClassicRules(boardSize, winLength, false), minimaxEngine{Depth: 9})
+	if err == nil {
+		t.Errorf("Ожидалась ошибка при обрыве ввода")
+	}
+}
+
+func TestClassicFromBoard(t *testing.T) {
+	b := [boardSize][boardSize]Player{{Cross, Empty, Empty}, {Empty, Circle, Empty}, {Empty, Empty, Empty}}
+	r := classicFromBoard(b, Cross)
+	if !reflect.DeepEqual(r.Cells, []Player{Cross, 0, 0, 0, Circle, 0, 0, 0, 0}) || r.ToMove() != Cross {
+		t.Errorf("Неверное преобразование поля: %v", r)
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// winScore больше любой эвристической оценки; к нему добавляется оставшаяся глубина,
+// чтобы быстрый выигрыш ценился выше долгого.
+const (
+	winScore = 1000000
+	inf      = math.MaxInt32
+)
+
+// deadlineCheckInterval — через сколько позиций поиск сверяется с часами.
+const deadlineCheckInterval = 1024
+
+// pvMaxLength — наибольшая длина главного варианта, сообщаемого наблюдателю поиска.
+const pvMaxLength = 6
+
+// SearchInfo описывает проделанный поиск: достигнутую глубину, число позиций и время.
+type SearchInfo struct {
+	Depth   int
+	Nodes   int
+	Elapsed time.Duration
+}
+
+// searcher хранит состояние одного поиска: счётчик позиций и срок, после которого
+// перебор прерывается. Нулевой deadline означает поиск без ограничения времени.
+// Отмена ctx прерывает поиск в любой момент, в том числе на глубине 1.
+type searcher struct {
+	ctx      context.Context
+	deadline time.Time
+	nodes    int
+	aborted  bool
+	// cutoff отмечает, что перебор упёрся в глубину, а не дошёл до конца всех партий.
+	cutoff bool
+}
+
+// terminalScore оценивает окончившуюся партию с точки зрения ходящего.
+func terminalScore(r Rules, depth int) int {
+	switch r.Winner() {
+	case Empty:
+		return 0
+	case r.ToMove():
+		return winScore + depth
+	default:
+		return -winScore - depth
+	}
+}
+
+// search перебирает позиции на глубину depth в форме негамакса с альфа-бета
+// отсечением и возвращает оценку с точки зрения ходящего. После истечения срока
+// возвращает 0 и выставляет aborted; такой результат использовать нельзя.
+func (s *searcher) search(r Rules, depth, alpha, beta int) int {
+	s.nodes++
+	if s.nodes%deadlineCheckInterval == 0 {
+		if s.ctx != nil && s.ctx.Err() != nil || !s.deadline.IsZero() && time.Now().After(s.deadline) {
+			s.aborted = true
+		}
+	}
+	if s.aborted {
+		return 0
+	}
+	if r.Terminal() {
+		return terminalScore(r, depth)
+	}
+	if depth == 0 {
+		s.cutoff = true
+		return r.Evaluate()
+	}
+
+	best := -inf
+	for _, m := range r.LegalMoves() {
+		best = max(best, -s.search(r.Apply(m), depth-1, -beta, -alpha))
+		alpha = max(alpha, best)
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// root выбирает лучший из ходов moves, перебирая на глубину depth.
+// При равных оценках выбирается первый ход.
+func (s *searcher) root(r Rules, moves []Move, depth int) (Move, int) {
+	best, bestVal := moves[0], -inf
+	for _, m := range moves {
+		// Ходы, не превосходящие текущий лучший, можно оценивать лишь приблизительно.
+		val := -s.search(r.Apply(m), depth-1, -inf, -bestVal)
+		if s.aborted {
+			break
+		}
+		if val > bestVal {
+			best, bestVal = m, val
+		}
+	}
+	return best, bestVal
+}
+
+// rootParallel делает то же, что root, распределяя ходы между workers горутинами.
+// Выбирается первый из ходов с наибольшей оценкой. Ход позже текущего лучшего
+// ищется с окном, доказывающим лишь, что он не лучше, а ход раньше лучшего — что
+// он хуже, поэтому точную оценку получают только ходы, которые могут стать выбранными.
+// Так выбранный ход и его оценка совпадают с последовательным поиском при любом
+// порядке выполнения горутин. Первый ход ищется до их запуска, чтобы у остальных
+// сразу была граница окна.
+func (s *searcher) rootParallel(r Rules, moves []Move, depth, workers int) (Move, int) {
+	if workers <= 1 || len(moves) == 1 {
+		return s.root(r, moves, depth)
+	}
+
+	first := -s.search(r.Apply(moves[0]), depth-1, -inf, inf)
+	if s.aborted {
+		return moves[0], first
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		next    = 1
+		bestVal = first
+		bestIdx = 0
+	)
+	for w := 0; w < min(workers, len(moves)-1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ws := searcher{ctx: s.ctx, deadline: s.deadline}
+			for {
+				mu.Lock()
+				i, alpha := next, bestVal
+				if i < bestIdx {
+					alpha--
+				}
+				next++
+				mu.Unlock()
+				if i >= len(moves) {
+					break
+				}
+				val := -ws.search(r.Apply(moves[i]), depth-1, -inf, -alpha)
+				if ws.aborted {
+					break
+				}
+				mu.Lock()
+				if val > bestVal || val == bestVal && i < bestIdx {
+					bestVal, bestIdx = val, i
+				}
+				mu.Unlock()
+			}
+
+			mu.Lock()
+			s.nodes += ws.nodes
+			s.aborted = s.aborted || ws.aborted
+			s.cutoff = s.cutoff || ws.cutoff
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return moves[bestIdx], bestVal
+}
+
+// minimax перебирает позиции на глубину depth без ограничения времени
+// и возвращает оценку с точки зрения ходящего.
+func minimax(r Rules, depth, alpha, beta int) int {
+	var s searcher
+	return s.search(r, depth, alpha, beta)
+}
+
+// bestMove выбирает для ходящего ход с наибольшей оценкой при переборе на глубину depth.
+// При равных оценках выбирается первый ход из LegalMoves.
+func bestMove(r Rules, depth int) (Move, int, bool) {
+	moves := r.LegalMoves()
+	if len(moves) == 0 {
+		return Move{}, 0, false
+	}
+	var s searcher
+	m, val := s.root(r, moves, depth)
+	return m, val, true
+}
+
+// iterativeDeepening перебирает workers горутинами на глубину 1, 2, 3 и далее, пока не истечёт budget
+// или не будет достигнута maxDepth, и возвращает лучший ход последней завершённой глубины.
+// Глубина 1 всегда досчитывается до конца, чтобы ход нашёлся при любом бюджете,
+// если только ctx не отменён: тогда ok ложно.
+// Лучший ход предыдущей глубины проверяется первым: так отсечений больше.
+func iterativeDeepening(ctx context.Context, r Rules, maxDepth, workers int, budget time.Duration) (Move, int, SearchInfo, bool) {
+	return deepen(ctx, r, maxDepth, workers, budget, nil)
+}
+
+// deepen — итеративное углубление, как iterativeDeepening, но после каждой
+// завершённой глубины вызывает onDepth с лучшим ходом, его оценкой и сведениями о поиске.
+func deepen(ctx context.Context, r Rules, maxDepth, workers int, budget time.Duration, onDepth func(Move, int, SearchInfo)) (Move, int, SearchInfo, bool) {
+	start := time.Now()
+	moves := r.LegalMoves()
+	if len(moves) == 0 {
+		return Move{}, 0, SearchInfo{}, false
+	}
+
+	s := searcher{ctx: ctx}
+	var info SearchInfo
+	best, bestVal := moves[0], 0
+	for depth := 1; depth <= maxDepth; depth++ {
+		if depth == 2 {
+			s.deadline = start.Add(budget)
+		}
+		if depth > 1 && time.Now().After(s.deadline) {
+			break
+		}
+		s.cutoff = false
+		m, val := s.rootParallel(r, moves, depth, workers)
+		if s.aborted {
+			break
+		}
+		best, bestVal = m, val
+		info.Depth = depth
+		if onDepth != nil {
+			onDepth(best, bestVal, SearchInfo{Depth: depth, Nodes: s.nodes, Elapsed: time.Since(start)})
+		}
+
+		// Выигрыш или проигрыш доказан, либо все партии доиграны до конца: глубже искать незачем.
+		if val >= winScore || val <= -winScore || !s.cutoff {
+			break
+		}
+		for i := range moves {
+			if moves[i] == best {
+				moves[0], moves[i] = moves[i], moves[0]
+				break
+			}
+		}
+	}
+
+	info.Nodes = s.nodes
+	info.Elapsed = time.Since(start)
+	if ctx.Err() != nil {
+		return Move{}, 0, info, false
+	}
+	return best, bestVal, info, true
+}
+
+// principalVariation продолжает ход m лучшими ответами сторон, перебирая каждую
+// следующую позицию на полуход мельче, но не длиннее pvMaxLength ходов.
+// Перебор прекращается к сроку deadline или при отмене ctx, и тогда вариант короче.
+func principalVariation(ctx context.Context, deadline time.Time, r Rules, m Move, depth int) []Move {
+	pv := []Move{m}
+	r = r.Apply(m)
+	for d := depth - 1; d > 0 && len(pv) < pvMaxLength && !r.Terminal(); d-- {
+		s := searcher{ctx: ctx, deadline: deadline}
+		next, _ := s.root(r, r.LegalMoves(), d)
+		if s.aborted {
+			break
+		}
+		pv = append(pv, next)
+		r = r.Apply(next)
+	}
+	return pv
+}
+
+// formatSearchInfo записывает сведения о поиске одной строкой.
+func formatSearchInfo(info SearchInfo) string {
+	return fmt.Sprintf("depth %d, %d nodes, %s", info.Depth, info.Nodes, info.Elapsed.Round(time.Millisecond))
+}
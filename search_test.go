@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestIterativeDeepeningMatchesFullSearch(t *testing.T) {
+	// С запасом времени итеративное углубление доходит до конца партии и даёт ту же оценку.
+	r := newClassicRules(boardSize, winLength, false)
+	_, full, _ := bestMove(r, 9)
+	_, val, info, ok := iterativeDeepening(context.Background(), r, 9, 1, time.Minute)
+	if !ok || val != full || info.Depth != 9 || info.Nodes == 0 {
+		t.Errorf("Ожидалась оценка %d на глубине 9, но получено %d, %+v", full, val, info)
+	}
+}
+
+func TestIterativeDeepeningStopsEarly(t *testing.T) {
+	// Выигрыш в один ход найден на глубине 1, дальше искать не нужно.
+	r := Rules(newClassicRules(boardSize, winLength, false))
+	for _, m := range []Move{{Row: 0, Col: 0}, {Row: 1, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}} {
+		r = r.Apply(m)
+	}
+	m, _, info, ok := iterativeDeepening(context.Background(), r, 9, 1, time.Minute)
+	if !ok || m.Row != 0 || m.Col != 2 || info.Depth != 1 {
+		t.Errorf("Ожидался ход (0, 2) на глубине 1, но получен %v, %+v", m, info)
+	}
+}
+
+func TestIterativeDeepeningBudget(t *testing.T) {
+	// На пустом поле 5×5 полный перебор невозможен: поиск должен уложиться во время.
+	budget := 50 * time.Millisecond
+	start := time.Now()
+	_, _, info, ok := iterativeDeepening(context.Background(), newClassicRules(5, 4, false), 25, 1, budget)
+	if !ok || info.Depth < 1 || info.Depth >= 25 {
+		t.Errorf("Неожиданная глубина поиска: %+v", info)
+	}
+	if elapsed := time.Since(start); elapsed > budget+time.Second {
+		t.Errorf("Поиск занял %v при бюджете %v", elapsed, budget)
+	}
+
+	// Даже при почти нулевом бюджете глубина 1 досчитывается.
+	if _, _, info, ok := iterativeDeepening(context.Background(), newClassicRules(5, 4, false), 25, 1, time.Nanosecond); !ok || info.Depth != 1 {
+		t.Errorf("Ожидалась глубина 1, но получено %+v", info)
+	}
+}
+
+func TestMinimaxEngineInfo(t *testing.T) {
+	_, info, ok := minimaxEngine{Depth: 3}.BestMove(context.Background(), newClassicRules(boardSize, winLength, false))
+	if !ok || info.Depth != 3 || info.Nodes == 0 {
+		t.Errorf("Неверные сведения о поиске: %+v", info)
+	}
+}
+
+// progressLog запоминает итоги перебора, о которых сообщает движок.
+type progressLog []SearchProgress
+
+func (l *progressLog) observe(sp SearchProgress) {
+	*l = append(*l, sp)
+}
+
+func TestMinimaxEngineObserver(t *testing.T) {
+	// При поиске по времени наблюдатель получает итог каждой глубины с главным вариантом.
+	var log progressLog
+	r := newClassicRules(boardSize, winLength, false)
+	m, info, ok := minimaxEngine{Depth: 4, Budget: time.Minute, Workers: 2, Observer: &log}.BestMove(context.Background(), r)
+	if !ok || len(log) != info.Depth {
+		t.Fatalf("Сообщено %d глубин из %d", len(log), info.Depth)
+	}
+	for i, sp := range log {
+		if sp.Info.Depth != i+1 || len(sp.PV) != min(i+1, pvMaxLength) || sp.PV[0] != sp.Move {
+			t.Errorf("Глубина %d: неверный итог %+v", i+1, sp)
+		}
+	}
+	if last := log[len(log)-1]; last.Move != m {
+		t.Errorf("Последний итог %v, выбран ход %v", last.Move, m)
+	}
+
+	// Выигрыш угрозами сообщается одним итогом со всей последовательностью ходов.
+	log = nil
+	win := Rules(r)
+	for _, mv := range []Move{{Row: 0, Col: 0}, {Row: 1, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}} {
+		win = win.Apply(mv)
+	}
+	minimaxEngine{Depth: 9, Observer: &log}.BestMove(context.Background(), win)
+	if len(log) != 1 || log[0].Score < winScore || len(log[0].PV) == 0 || log[0].PV[0] != (Move{Row: 0, Col: 2, Symbol: Cross}) {
+		t.Errorf("Неверный итог выигрыша: %+v", log)
+	}
+}
+
+func TestRootParallelDeterministic(t *testing.T) {
+	// На любых позициях параллельный перебор выбирает тот же ход с той же оценкой, что и последовательный.
+	rnd := rand.New(rand.NewSource(1))
+	positions := []struct {
+		r     Rules
+		depth int
+	}{
+		{newClassicRules(boardSize, winLength, false), 9},
+		{newClassicRules(4, 3, false), 3},
+		{newClassicRules(5, 4, false), 2},
+	}
+	g := newGravityBoard()
+	positions = append(positions, struct {
+		r     Rules
+		depth int
+	}{&g, 4})
+
+	for _, p := range positions {
+		r := p.r
+		for ply := 0; ply < 6 && !r.Terminal(); ply++ {
+			want, wantVal, _ := bestMove(r, p.depth)
+			for _, workers := range []int{2, 8} {
+				for run := 0; run < 2; run++ {
+					s := searcher{ctx: context.Background()}
+					m, val := s.rootParallel(r, r.LegalMoves(), p.depth, workers)
+					if m != want || val != wantVal {
+						t.Fatalf("%s, %d горутин: ожидался ход %v (%d), но получен %v (%d)", r.Name(), workers, want, wantVal, m, val)
+					}
+				}
+			}
+			moves := r.LegalMoves()
+			r = r.Apply(moves[rnd.Intn(len(moves))])
+		}
+	}
+}
+
+func benchmarkRoot(b *testing.B, r Rules, depth int) {
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			e := minimaxEngine{Depth: depth, Workers: workers}
+			for i := 0; i < b.N; i++ {
+				e.BestMove(context.Background(), r)
+			}
+		})
+	}
+}
+
+func BenchmarkRootSearch4x4(b *testing.B) {
+	benchmarkRoot(b, newClassicRules(4, 4, false), 8)
+}
+
+func BenchmarkRootSearch5x5(b *testing.B) {
+	benchmarkRoot(b, newClassicRules(5, 4, false), 5)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	statsDirName  = "notlosetictactoe"
+	statsFileName = "stats.json"
+)
+
+// GameRecord описывает одну завершённую партию.
+type GameRecord struct {
+	Players    [2]string     `json:"players"`
+	Opponent   string        `json:"opponent"`
+	Mode       string        `json:"mode"`
+	Result     string        `json:"result"`
+	Moves      int           `json:"moves"`
+	Duration   time.Duration `json:"duration"`
+	FinishedAt time.Time     `json:"finished_at"`
+}
+
+// Результаты партии с точки зрения первого игрока из GameRecord.Players.
+const (
+	resultWin  = "win"
+	resultDraw = "draw"
+	resultLoss = "loss"
+)
+
+// StatsSummary содержит количество побед, ничьих и поражений против одного типа соперника.
+type StatsSummary struct {
+	Opponent string
+	Wins     int
+	Draws    int
+	Losses   int
+}
+
+// statsFile хранит путь к файлу статистики; пустая строка отключает запись.
+var statsFile string
+
+// defaultStatsPath возвращает путь к файлу статистики в каталоге настроек пользователя.
+func defaultStatsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, statsDirName, statsFileName), nil
+}
+
+// loadStats читает все записи из файла. Отсутствующий файл означает пустую историю.
+func loadStats(path string) ([]GameRecord, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []GameRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("чтение статистики %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// appendStats добавляет запись в файл статистики, создавая каталог при необходимости.
+func appendStats(path string, rec GameRecord) error {
+	records, err := loadStats(path)
+	if err != nil {
+		return err
+	}
+	records = append(records, rec)
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы не испортить историю при сбое.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// summarizeStats группирует записи по типу соперника.
+func summarizeStats(records []GameRecord) []StatsSummary {
+	byOpponent := make(map[string]*StatsSummary)
+	for _, rec := range records {
+		s, ok := byOpponent[rec.Opponent]
+		if !ok {
+			s = &StatsSummary{Opponent: rec.Opponent}
+			byOpponent[rec.Opponent] = s
+		}
+		switch rec.Result {
+		case resultWin:
+			s.Wins++
+		case resultDraw:
+			s.Draws++
+		case resultLoss:
+			s.Losses++
+		}
+	}
+
+	result := make([]StatsSummary, 0, len(byOpponent))
+	for _, s := range byOpponent {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Opponent < result[j].Opponent
+	})
+	return result
+}
+
+// formatStats возвращает текстовую таблицу побед, ничьих и поражений.
+func formatStats(records []GameRecord) string {
+	if len(records) == 0 {
+		return "No games recorded yet.\n"
+	}
+	out := fmt.Sprintf("%-10s %5s %5s %5s\n", "Opponent", "Win", "Draw", "Loss")
+	for _, s := range summarizeStats(records) {
+		out += fmt.Sprintf("%-10s %5d %5d %5d\n", s.Opponent, s.Wins, s.Draws, s.Losses)
+	}
+	return out
+}
+
+// runStats реализует подкоманду "stats": печатает историю партий.
+func runStats(w io.Writer, path string) error {
+	records, err := loadStats(path)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, formatStats(records))
+	return err
+}
+
+// resultFor переводит победителя партии в результат для игрока, играющего фишками p.
+func resultFor(w, p Player) string {
+	switch w {
+	case Empty:
+		return resultDraw
+	case p:
+		return resultWin
+	default:
+		return resultLoss
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "stats.json")
+
+	// Отсутствующий файл означает пустую историю.
+	records, err := loadStats(path)
+	if err != nil || len(records) != 0 {
+		t.Fatalf("Ожидалась пустая история, но получено %v (ошибка %v)", records, err)
+	}
+
+	recs := []GameRecord{
+		{Players: [2]string{"human", "human"}, Opponent: "human", Mode: "classic", Result: resultWin, Moves: 5, Duration: 3 * time.Second},
+		{Players: [2]string{"human", "ai"}, Opponent: "ai", Mode: "classic", Result: resultDraw, Moves: 9, Duration: 10 * time.Second},
+		{Players: [2]string{"human", "human"}, Opponent: "human", Mode: "classic", Result: resultLoss, Moves: 6, Duration: time.Second},
+	}
+	for _, rec := range recs {
+		if err := appendStats(path, rec); err != nil {
+			t.Fatalf("Ошибка записи: %v", err)
+		}
+	}
+
+	records, err = loadStats(path)
+	if err != nil {
+		t.Fatalf("Ошибка чтения: %v", err)
+	}
+	if !reflect.DeepEqual(records, recs) {
+		t.Errorf("Ожидалось %v, но получено %v", recs, records)
+	}
+
+	expected := []StatsSummary{
+		{Opponent: "ai", Draws: 1},
+		{Opponent: "human", Wins: 1, Losses: 1},
+	}
+	if summary := summarizeStats(records); !reflect.DeepEqual(summary, expected) {
+		t.Errorf("Ожидалось %v, но получено %v", expected, summary)
+	}
+
+	var out bytes.Buffer
+	if err := runStats(&out, path); err != nil {
+		t.Fatalf("Ошибка подкоманды stats: %v", err)
+	}
+	if !strings.Contains(out.String(), "human") || !strings.Contains(out.String(), "ai") {
+		t.Errorf("В выводе нет типов соперников: %q", out.String())
+	}
+}
+
+func TestResultFor(t *testing.T) {
+	cases := map[Player]string{Circle: resultWin, Cross: resultLoss, Empty: resultDraw}
+	for w, expected := range cases {
+		if result := resultFor(w, Circle); result != expected {
+			t.Errorf("Ожидалось %s, но получено %s", expected, result)
+		}
+	}
+
+	if result := resultFor(Cross, Cross); result != resultWin {
+		t.Errorf("Ожидалось %s, но получено %s", resultWin, result)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import "context"
+
+const (
+	// vcfMaxFours — наибольшее число угроз подряд, которое проверяет поиск выигрыша угрозами.
+	vcfMaxFours = 30
+	// vcfMaxNodes ограничивает число позиций, чтобы поиск угроз не задерживал основной перебор.
+	vcfMaxNodes = 200000
+)
+
+// forcedWinner реализуют варианты, умеющие искать форсированный выигрыш одними угрозами.
+// Компьютер проверяет его до основного перебора.
+type forcedWinner interface {
+	// ForcedWin возвращает последовательность ходов, начиная с хода ходящего, которая
+	// выигрывает при любой защите, и число рассмотренных позиций. Отмена ctx или его
+	// срок прерывают поиск, и тогда выигрыш считается ненайденным.
+	ForcedWin(ctx context.Context) (seq []Move, nodes int, ok bool)
+}
+
+// threatCells возвращает клетки, каждая из которых сразу собирает линию игрока p.
+func (r *ClassicRules) threatCells(p Player) []Cell {
+	var cells []Cell
+	seen := make(map[Cell]bool)
+	for _, line := range r.Lines {
+		own, empty := 0, noCell
+		for _, c := range line {
+			switch r.Cells[c.Row*r.Size+c.Col] {
+			case p:
+				own++
+			case Empty:
+				empty = c
+			}
+		}
+		if own == r.K-1 && empty != noCell && !seen[empty] {
+			seen[empty] = true
+			cells = append(cells, empty)
+		}
+	}
+	return cells
+}
+
+// fourMoves возвращает ходы игрока p, после которых у него появляется угроза
+// собрать линию: пустые клетки открытых отрезков, где не хватает двух фишек.
+func (r *ClassicRules) fourMoves(p Player) []Cell {
+	var cells []Cell
+	seen := make(map[Cell]bool)
+	for _, line := range r.Lines {
+		own, empties := 0, make([]Cell, 0, 2)
+		for _, c := range line {
+			switch r.Cells[c.Row*r.Size+c.Col] {
+			case p:
+				own++
+			case Empty:
+				empties = append(empties, c)
+			}
+		}
+		if own != r.K-2 || len(empties) != 2 {
+			continue
+		}
+		for _, c := range empties {
+			if !seen[c] {
+				seen[c] = true
+				cells = append(cells, c)
+			}
+		}
+	}
+	return cells
+}
+
+// vcfSearch хранит состояние поиска выигрыша непрерывными угрозами (VCF):
+// атакующий делает только ходы, создающие угрозу, а защищающийся обязан её закрыть.
+// Поэтому на каждом шаге у защиты ровно один ответ, и поиск уходит гораздо глубже минимакса.
+type vcfSearch struct {
+	ctx     context.Context
+	nodes   int
+	aborted bool
+	failed  map[string]int // Позиции, где выигрыша нет, с проверенным запасом угроз.
+}
+
+// ForcedWin ищет выигрыш непрерывными угрозами для ходящего. В мизере угрозы
+// работают против того, кто их создаёт, поэтому поиск не ведётся.
+func (r *ClassicRules) ForcedWin(ctx context.Context) ([]Move, int, bool) {
+	if r.Misere || r.Terminal() {
+		return nil, 0, false
+	}
+	s := vcfSearch{ctx: ctx, failed: make(map[string]int)}
+	seq, ok := s.search(r, vcfMaxFours)
+	return seq, s.nodes, ok
+}
+
+func (s *vcfSearch) search(r *ClassicRules, fours int) ([]Move, bool) {
+	s.nodes++
+	attacker, defender := r.Turn, other(r.Turn)
+
+	// Выигрыш в один ход.
+	if wins := r.threatCells(attacker); len(wins) > 0 {
+		return []Move{{Row: wins[0].Row, Col: wins[0].Col, Symbol: attacker}}, true
+	}
+	if s.nodes%deadlineCheckInterval == 1 && s.ctx.Err() != nil {
+		s.aborted = true
+	}
+	if fours == 0 || s.nodes > vcfMaxNodes || s.aborted {
+		return nil, false
+	}
+	key := string(playerBytes(r.Cells))
+	if left, ok := s.failed[key]; ok && left >= fours {
+		return nil, false
+	}
+
+	// Если соперник сам грозит выиграть, угрозу можно создавать только в клетке,
+	// которая закрывает его линию; две угрозы соперника не закрыть одним ходом.
+	blocks := r.threatCells(defender)
+	if len(blocks) > 1 {
+		s.failed[key] = fours
+		return nil, false
+	}
+
+	for _, c := range r.fourMoves(attacker) {
+		if len(blocks) == 1 && c != blocks[0] {
+			continue
+		}
+		m := Move{Row: c.Row, Col: c.Col, Symbol: attacker}
+		next := r.Apply(m).(*ClassicRules)
+		threats := next.threatCells(attacker)
+		if len(threats) >= 2 {
+			// Две угрозы сразу: защита закрывает одну, вторая выигрывает.
+			reply := Move{Row: threats[0].Row, Col: threats[0].Col, Symbol: defender}
+			win := Move{Row: threats[1].Row, Col: threats[1].Col, Symbol: attacker}
+			return []Move{m, reply, win}, true
+		}
+		if len(threats) == 0 {
+			continue
+		}
+
+		reply := Move{Row: threats[0].Row, Col: threats[0].Col, Symbol: defender}
+		after := next.Apply(reply).(*ClassicRules)
+		if after.Terminal() {
+			continue
+		}
+		if rest, ok := s.search(after, fours-1); ok {
+			return append([]Move{m, reply}, rest...), true
+		}
+		if s.aborted {
+			return nil, false
+		}
+	}
+	s.failed[key] = fours
+	return nil, false
+}
+
+// playerBytes переводит клетки поля в байты для использования в ключе карты.
+func playerBytes(cells []Player) []byte {
+	b := make([]byte, len(cells))
+	for i, p := range cells {
+		b[i] = byte(p)
+	}
+	return b
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// classicFromText строит позицию по строкам вида "..X.O": X — крестики, O — нолики.
+func classicFromText(k int, turn Player, rows ...string) *ClassicRules {
+	r := newClassicRules(len(rows), k, false)
+	for i, row := range rows {
+		for j, ch := range row {
+			switch ch {
+			case 'X':
+				r.Cells[i*r.Size+j] = Cross
+			case 'O':
+				r.Cells[i*r.Size+j] = Circle
+			}
+		}
+	}
+	r.Turn = turn
+	return r
+}
+
+// checkVCF проигрывает последовательность и проверяет, что каждый ход атакующего
+// создаёт угрозу, ответ защиты её закрывает, а последний ход выигрывает.
+func checkVCF(t *testing.T, r *ClassicRules, seq []Move) {
+	t.Helper()
+	attacker := r.Turn
+	for i, m := range seq {
+		if i%2 == 1 {
+			threats := r.threatCells(attacker)
+			if len(threats) == 0 {
+				t.Fatalf("Ход %d: атакующий не создал угрозы", i)
+			}
+			if len(r.threatCells(other(attacker))) > 0 {
+				t.Fatalf("Ход %d: защита может выиграть сразу", i)
+			}
+			if len(threats) == 1 && (threats[0].Row != m.Row || threats[0].Col != m.Col) {
+				t.Fatalf("Ход %d: защита не закрыла единственную угрозу", i)
+			}
+		}
+		r = r.Apply(m).(*ClassicRules)
+	}
+	if r.Winner() != attacker {
+		t.Fatalf("Последовательность не выигрывает: победитель %v", r.Winner())
+	}
+}
+
+func TestForcedWinDeep(t *testing.T) {
+	r := classicFromText(5, Cross,
+		".........",
+		".........",
+		"...O..O..",
+		".....X...",
+		"..X...X..",
+		"...X.....",
+		".OX.X....",
+		".......OO",
+		".......O.",
+	)
+	seq, nodes, ok := r.ForcedWin(context.Background())
+	if !ok || nodes == 0 {
+		t.Fatal("Выигрыш угрозами не найден")
+	}
+	// Выигрыш лежит глубже, чем видит перебор компьютера на этом поле.
+	if len(seq) <= 5 {
+		t.Errorf("Ожидалась длинная последовательность, но получено %d ходов", len(seq))
+	}
+	checkVCF(t, r, seq)
+
+	// Компьютер находит первый ход выигрыша даже при переборе на один полуход.
+	m, _, ok := minimaxEngine{Depth: 1}.BestMove(context.Background(), r)
+	if !ok || m != seq[0] {
+		t.Errorf("Ожидался ход %v, но получен %v", seq[0], m)
+	}
+}
+
+func TestForcedWinCancel(t *testing.T) {
+	// Отменённый поиск не находит выигрыш, который иначе нашёл бы.
+	r := classicFromText(3, Cross,
+		"X..",
+		".O.",
+		"O.X",
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if seq, _, ok := r.ForcedWin(ctx); ok {
+		t.Errorf("Отменённый поиск вернул выигрыш %v", seq)
+	}
+}
+
+func TestForcedWinDoubleThreat(t *testing.T) {
+	// Крестики закрывают угрозу ноликов в (0, 2) и этим же ходом создают две своих.
+	r := classicFromText(3, Cross,
+		"X..",
+		".O.",
+		"O.X",
+	)
+	seq, _, ok := r.ForcedWin(context.Background())
+	if !ok || len(seq) != 3 || seq[0].Row != 0 || seq[0].Col != 2 {
+		t.Fatalf("Ожидался выигрыш ходом (0, 2), но получено %v", seq)
+	}
+	checkVCF(t, r, seq)
+}
+
+func TestForcedWinRespectsDefence(t *testing.T) {
+	// У ноликов угроза в (0, 2): крестики обязаны закрыть её, и выигрыша угрозами нет.
+	r := classicFromText(3, Cross,
+		"OO.",
+		"X..",
+		"...",
+	)
+	if seq, _, ok := r.ForcedWin(context.Background()); ok {
+		t.Errorf("Найден несуществующий выигрыш %v", seq)
+	}
+
+	// Две угрозы соперника одним ходом не закрыть.
+	r = classicFromText(4, Cross,
+		"OOO.",
+		"....",
+		"OOO.",
+		"XX..",
+	)
+	if seq, _, ok := r.ForcedWin(context.Background()); ok {
+		t.Errorf("Найден выигрыш при двух угрозах соперника %v", seq)
+	}
+}
+
+func TestForcedWinMisere(t *testing.T) {
+	r := classicFromText(3, Cross,
+		"X..",
+		"...",
+		"..O",
+	)
+	r.Misere = true
+	if _, _, ok := r.ForcedWin(context.Background()); ok {
+		t.Errorf("В мизере выигрыш угрозами не ищется")
+	}
+}
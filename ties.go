@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StyleWeights — веса стиля игры: насколько охотно компьютер выбирает центр, углы
+// и остальные клетки среди одинаково хороших ходов. Нулевые веса означают равный выбор.
+type StyleWeights struct {
+	Center, Corner, Edge float64
+}
+
+// parseStyle разбирает веса стиля вида "center:3,corner:2,edge:1"; пропущенные веса равны нулю.
+func parseStyle(spec string) (StyleWeights, error) {
+	var w StyleWeights
+	for _, part := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(part, ":")
+		x, err := strconv.ParseFloat(value, 64)
+		if !ok || err != nil || x < 0 {
+			return w, fmt.Errorf("неверный вес стиля %q", part)
+		}
+		switch name {
+		case "center":
+			w.Center = x
+		case "corner":
+			w.Corner = x
+		case "edge":
+			w.Edge = x
+		default:
+			return w, fmt.Errorf("неизвестный вес стиля %q", name)
+		}
+	}
+	return w, nil
+}
+
+// weight возвращает вес клетки хода m на поле с подсказками h: центр определяется так же,
+// как в эвристике, углы — по краям слоя, все остальные клетки считаются краем.
+func (w StyleWeights) weight(h RenderHints, m Move) float64 {
+	c := m.To()
+	lastRow, lastCol := h.Rows-1, h.Cols-1
+	switch {
+	case c.Row >= lastRow/2 && c.Row <= h.Rows/2 && c.Col >= lastCol/2 && c.Col <= h.Cols/2:
+		return w.Center
+	case (c.Row == 0 || c.Row == lastRow) && (c.Col == 0 || c.Col == lastCol):
+		return w.Corner
+	}
+	return w.Edge
+}
+
+// tieBreaker выбирает ход среди одинаково хороших: равновероятно или по весам стиля.
+type tieBreaker struct {
+	Rand  *rand.Rand
+	Style StyleWeights
+}
+
+// newTieBreaker создаёт выбор с генератором из newEngineRand.
+func newTieBreaker(style StyleWeights) *tieBreaker {
+	return &tieBreaker{Rand: newEngineRand(), Style: style}
+}
+
+// choose выбирает один из ходов moves позиции r. Если у всех ходов нулевой вес стиля,
+// выбор равновероятный.
+func (t *tieBreaker) choose(r Rules, moves []Move) Move {
+	h := r.Hints()
+	total := 0.0
+	for _, m := range moves {
+		total += t.Style.weight(h, m)
+	}
+	if total == 0 {
+		return moves[t.Rand.Intn(len(moves))]
+	}
+	x := t.Rand.Float64() * total
+	for _, m := range moves {
+		x -= t.Style.weight(h, m)
+		if x < 0 {
+			return m
+		}
+	}
+	return moves[len(moves)-1]
+}
+
+// equalMoves возвращает ходы, оценка которых при переборе на глубину depth не ниже val,
+// то есть все лучшие ходы, если val — оценка лучшего. Каждый ход проверяется
+// перебором с нулевым окном, что дешевле точной оценки. ok ложно, если перебор прерван
+// отменой ctx или сроком deadline; нулевой deadline — без ограничения времени.
+func equalMoves(ctx context.Context, deadline time.Time, r Rules, moves []Move, depth, val int) ([]Move, bool) {
+	s := searcher{ctx: ctx, deadline: deadline}
+	var best []Move
+	for _, m := range moves {
+		if s.search(r.Apply(m), depth-1, -val, -val+1) <= -val {
+			best = append(best, m)
+		}
+		if s.aborted {
+			return nil, false
+		}
+	}
+	return best, true
+}
+
+// tieStyle — веса стиля выбора среди равных ходов для компьютера; nil — первый ход
+// из LegalMoves, поведение по умолчанию, на которое рассчитаны тесты.
+var tieStyle *StyleWeights
+
+// newTies создаёт выбор среди равных ходов для нового движка по стилю tieStyle
+// или возвращает nil, если выбор не нужен.
+func newTies() *tieBreaker {
+	if tieStyle == nil {
+		return nil
+	}
+	return newTieBreaker(*tieStyle)
+}
+
+// breakTie выбирает ход правилом t среди ходов позиции r с той же оценкой val, что у найденного m.
+// Перебор равных ходов укладывается в тот же срок deadline, что и поиск m; без правила,
+// при отмене ctx или по истечении срока возвращается m.
+func breakTie(ctx context.Context, deadline time.Time, t *tieBreaker, r Rules, m Move, depth, val int) Move {
+	if t == nil {
+		return m
+	}
+	best, ok := equalMoves(ctx, deadline, r, r.LegalMoves(), depth, val)
+	if !ok || len(best) == 0 {
+		return m
+	}
+	return t.choose(r, best)
+}
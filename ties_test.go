@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestParseStyle(t *testing.T) {
+	w, err := parseStyle("center:3,corner:2.5,edge:0")
+	if err != nil || w != (StyleWeights{Center: 3, Corner: 2.5}) {
+		t.Errorf("parseStyle = %+v, %v", w, err)
+	}
+	for _, spec := range []string{"", "center", "center:x", "corner:-1", "side:1"} {
+		if _, err := parseStyle(spec); err == nil {
+			t.Errorf("parseStyle(%q): ожидалась ошибка", spec)
+		}
+	}
+}
+
+func TestEqualMoves(t *testing.T) {
+	// С пустого поля любой ход ведёт к ничьей.
+	r := newBitRules(boardSize, winLength, false)
+	_, val, _ := bestMove(r, 9)
+	best, ok := equalMoves(context.Background(), time.Time{}, r, r.LegalMoves(), 9, val)
+	if !ok || len(best) != 9 {
+		t.Errorf("На пустом поле равны %d ходов вместо 9", len(best))
+	}
+
+	// После хода крестиков в угол ничью сохраняет только центр.
+	r = bitRulesFromClassic(classicFromText(3, Circle, "X..", "...", "..."))
+	_, val, _ = bestMove(r, 8)
+	best, _ = equalMoves(context.Background(), time.Time{}, r, r.LegalMoves(), 8, val)
+	if len(best) != 1 || best[0].Row != 1 || best[0].Col != 1 {
+		t.Errorf("Ожидался единственный ход в центр, получено %v", best)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r = newBitRules(4, 4, false)
+	if _, ok := equalMoves(ctx, time.Time{}, r, r.LegalMoves(), 8, 0); ok {
+		t.Errorf("Отменённый перебор не прерван")
+	}
+}
+
+func TestTieBreakerChoose(t *testing.T) {
+	r := newBitRules(boardSize, winLength, false)
+	moves := r.LegalMoves()
+
+	uniform := &tieBreaker{Rand: rand.New(rand.NewSource(1))}
+	seen := make(map[Move]int)
+	for i := 0; i < 900; i++ {
+		seen[uniform.choose(r, moves)]++
+	}
+	for _, m := range moves {
+		if seen[m] < 50 {
+			t.Errorf("Ход %v выбран %d раз из 900", m, seen[m])
+		}
+	}
+
+	// Только центр имеет вес — выбирается только он.
+	center := &tieBreaker{Rand: rand.New(rand.NewSource(1)), Style: StyleWeights{Center: 1}}
+	for i := 0; i < 100; i++ {
+		if m := center.choose(r, moves); m.Row != 1 || m.Col != 1 {
+			t.Fatalf("Выбран ход %v вместо центра", m)
+		}
+	}
+
+	// Углы вчетверо охотнее краёв: на 4 угла приходится около 4/5 выборов.
+	corners := &tieBreaker{Rand: rand.New(rand.NewSource(1)), Style: StyleWeights{Corner: 4, Edge: 1}}
+	n := 0
+	for i := 0; i < 1000; i++ {
+		m := corners.choose(r, moves)
+		if (m.Row == 1) != (m.Col == 1) {
+			continue
+		}
+		if m.Row == 1 {
+			t.Fatalf("Выбран центр с нулевым весом")
+		}
+		n++
+	}
+	if n < 750 || n > 850 {
+		t.Errorf("Углы выбраны %d раз из 1000", n)
+	}
+}
+
+func TestMinimaxEngineTies(t *testing.T) {
+	// Со случайным выбором первый ход меняется, но остаётся лучшим.
+	r := newBitRules(boardSize, winLength, false)
+	e := minimaxEngine{Depth: 9, Ties: &tieBreaker{Rand: testRand(t)}}
+	seen := make(map[Move]bool)
+	for i := 0; i < 20; i++ {
+		m, _, ok := e.BestMove(context.Background(), r)
+		if !ok {
+			t.Fatal("Ход не найден")
+		}
+		seen[m] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("Первый ход не меняется: %v", seen)
+	}
+
+	// Единственный лучший ход выбирается всегда, в том числе при поиске по времени.
+	r = bitRulesFromClassic(classicFromText(3, Circle, "X..", "...", "..."))
+	e.Budget = 1 << 40
+	for i := 0; i < 10; i++ {
+		if m, _, _ := e.BestMove(context.Background(), r); m.Row != 1 || m.Col != 1 {
+			t.Fatalf("Выбран проигрывающий ход %v", m)
+		}
+	}
+}
+
+func TestBreakTie(t *testing.T) {
+	// Без правила ход не меняется, с правилом выбирается среди равных.
+	r := bitRulesFromClassic(classicFromText(3, Circle, "...", "...", "..."))
+	m, val, _ := bestMove(r, boardSize*boardSize)
+	rule := &tieBreaker{Rand: testRand(t)}
+	seen := make(map[Move]bool)
+	for i := 0; i < 20; i++ {
+		seen[breakTie(context.Background(), time.Time{}, rule, r, m, boardSize*boardSize, val)] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("breakTie не меняет ход: %v", seen)
+	}
+	if got := breakTie(context.Background(), time.Time{}, nil, r, m, boardSize*boardSize, val); got != m {
+		t.Errorf("Без правила ожидался ход %v, получено %v", m, got)
+	}
+}
+
+func TestTiesKeepBudget(t *testing.T) {
+	// Выбор среди равных ходов не выходит за время на ход.
+	r := newClassic(5, 4, false)
+	if _, ok := equalMoves(context.Background(), time.Now().Add(-time.Second), r, r.LegalMoves(), 6, 0); ok {
+		t.Error("Перебор равных ходов не остановился по сроку")
+	}
+
+	const budget = 200 * time.Millisecond
+	u := variants[modeUltimate].newRules()
+	e := minimaxEngine{Depth: 100, Budget: budget, Workers: 2, Ties: &tieBreaker{Rand: testRand(t)}}
+	start := time.Now()
+	if _, _, ok := e.BestMove(context.Background(), u); !ok {
+		t.Fatal("Ход не найден")
+	}
+	if elapsed := time.Since(start); elapsed > budget*3/2 {
+		t.Errorf("Поиск с выбором среди равных шёл %s при времени на ход %s", elapsed, budget)
+	}
+}
+
+func TestNewEngineTies(t *testing.T) {
+	// У каждого движка свой выбор среди равных ходов со своим генератором.
+	defer func() { tieStyle = nil }()
+	tieStyle = &StyleWeights{}
+	a, _ := newEngine("minimax:2", level, 9)
+	b, _ := newEngine("", "easy", 9)
+	ta, tb := a.(minimaxEngine).Ties, b.(minimaxEngine).Ties
+	if ta == nil || tb == nil || ta == tb || ta.Rand == tb.Rand {
+		t.Errorf("Движки делят выбор среди равных ходов: %p и %p", ta, tb)
+	}
+	tieStyle = nil
+	if e, _ := newEngine("minimax:2", level, 9); e.(minimaxEngine).Ties != nil {
+		t.Error("Без стиля выбор среди равных ходов должен быть детерминированным")
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// TournamentResult — итог матча двух движков с точки зрения первого из них.
+type TournamentResult struct {
+	Wins, Draws, Losses int
+	Moves               [2]int           // Число ходов каждого движка.
+	Thinking            [2]time.Duration // Суммарное время на ходы каждого движка.
+}
+
+// playMatch играет games партий между движками a и b, меняя их цвета после каждой партии:
+// в чётных партиях a играет крестиками и ходит первым.
+func playMatch(newRules func() Rules, a, b Engine, games int) TournamentResult {
+	var res TournamentResult
+	engines := [2]Engine{a, b}
+	for g := 0; g < games; g++ {
+		first := g % 2 // Индекс движка, играющего крестиками.
+		r := newRules()
+		for !r.Terminal() {
+			i := first
+			if r.ToMove() != Cross {
+				i = 1 - first
+			}
+			start := time.Now()
+			m, _, ok := engines[i].BestMove(context.Background(), r)
+			res.Thinking[i] += time.Since(start)
+			res.Moves[i]++
+			if !ok {
+				break
+			}
+			r = r.Apply(m)
+		}
+
+		aSide := Cross
+		if first == 1 {
+			aSide = Circle
+		}
+		switch resultFor(r.Winner(), aSide) {
+		case resultWin:
+			res.Wins++
+		case resultDraw:
+			res.Draws++
+		case resultLoss:
+			res.Losses++
+		}
+	}
+	return res
+}
+
+// avgThinking возвращает среднее время на ход i-го движка.
+func (res TournamentResult) avgThinking(i int) time.Duration {
+	if res.Moves[i] == 0 {
+		return 0
+	}
+	return res.Thinking[i] / time.Duration(res.Moves[i])
+}
+
+// runTournament реализует подкоманду "tournament": играет матч и печатает итог.
+func runTournament(w io.Writer, newRules func() Rules, a, b Engine, games int) error {
+	res := playMatch(newRules, a, b, games)
+	_, err := fmt.Fprintf(w, "%s: %s vs %s, %d games\n"+
+		"%-14s %5s %5s %5s %12s\n"+
+		"%-14s %5d %5d %5d %12s\n"+
+		"%-14s %5d %5d %5d %12s\n",
+		newRules().Name(), a.Name(), b.Name(), games,
+		"Engine", "Win", "Draw", "Loss", "Avg move",
+		a.Name(), res.Wins, res.Draws, res.Losses, res.avgThinking(0).Round(time.Microsecond),
+		b.Name(), res.Losses, res.Draws, res.Wins, res.avgThinking(1).Round(time.Microsecond))
+	return err
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPlayMatch(t *testing.T) {
+	// Полный перебор против себя на поле 3×3 всегда сводит вничью.
+	newRules := func() Rules { return newClassicRules(boardSize, winLength, false) }
+	res := playMatch(newRules, minimaxEngine{Depth: 9}, minimaxEngine{Depth: 9}, 2)
+	if res.Wins != 0 || res.Losses != 0 || res.Draws != 2 {
+		t.Errorf("Ожидались две ничьи, но получено %+v", res)
+	}
+	if res.Moves[0] != 9 || res.Moves[1] != 9 {
+		t.Errorf("Ожидалось по 9 ходов каждого движка за две партии, но получено %v", res.Moves)
+	}
+
+	// Перебор на один полуход проигрывает полному перебору хотя бы одну партию.
+	res = playMatch(newRules, minimaxEngine{Depth: 1}, minimaxEngine{Depth: 9}, 2)
+	if res.Wins != 0 || res.Losses == 0 {
+		t.Errorf("Слабый движок не должен выигрывать, но получено %+v", res)
+	}
+}
+
+func TestRunTournament(t *testing.T) {
+	var out bytes.Buffer
+	newRules := func() Rules { return newClassicRules(4, 3, false) }
+	if err := runTournament(&out, newRules, minimaxEngine{Depth: 2}, &mctsEngine{Iterations: 200}, 2); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"classic-4x4-k3", "minimax:2", "mcts:200", "2 games"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("В отчёте нет %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestParseEngine(t *testing.T) {
+	tests := map[string]string{
+		"minimax":    "minimax:5",
+		"minimax:3":  "minimax:3",
+		"mcts":       "mcts:10000",
+		"mcts:500":   "mcts:500",
+		"mcts:250ms": "mcts:250ms",
+	}
+	for spec, want := range tests {
+		e, err := parseEngine(spec, 5)
+		if err != nil || e.Name() != want {
+			t.Errorf("parseEngine(%q): ожидалось %s, но получено %v (%v)", spec, want, e, err)
+		}
+	}
+	for _, spec := range []string{"alphabeta", "minimax:0", "mcts:-1", "mcts:fast", "minimax:3:0s", "minimax:1s:2"} {
+		if _, err := parseEngine(spec, 5); err == nil {
+			t.Errorf("parseEngine(%q): ожидалась ошибка", spec)
+		}
+	}
+}
+
+func TestNewEngine(t *testing.T) {
+	// Явно заданный движок важнее уровня сложности.
+	if e, err := newEngine("mcts:100", "easy", 9); err != nil || e.Name() != "mcts:100" {
+		t.Errorf("Ожидался mcts:100, но получено %v (%v)", e, err)
+	}
+	if e, err := newEngine("", "easy", 9); err != nil || e.Name() != "minimax:2:100ms" {
+		t.Errorf("Ожидался minimax:2:100ms, но получено %v (%v)", e, err)
+	}
+	// Уровень не поднимает глубину выше глубины варианта.
+	if e, err := newEngine("", "medium", 1); err != nil || e.Name() != "minimax:1:300ms" {
+		t.Errorf("Ожидался minimax:1:300ms, но получено %v (%v)", e, err)
+	}
+	if _, err := newEngine("", "impossible", 9); err == nil {
+		t.Errorf("Ожидалась ошибка для неизвестного уровня")
+	}
+}
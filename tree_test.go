@@ -2,16 +2,12 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
 	"os"
 	"reflect"
 	"testing"
-	"time"
 )
 
 func TestAVLTree(t *testing.T) {
-	rand.Seed(time.Now().UnixNano())
-
 	// Тест для создания пустого дерева и вставки элементов.
 	t.Run("Insert", func(t *testing.T) {
 		tree := NewAVLTree(10)
@@ -177,22 +173,25 @@ func TestAVLTree(t *testing.T) {
 }
 
 func TestRandomInsertDelete(t *testing.T) {
-	rand.Seed(time.Now().UnixNano())
+	rnd := testRand(t)
 
 	// Тест для случайной вставки и удаления элементов.
 	t.Run("RandomInsertDelete", func(t *testing.T) {
 		tree := NewAVLTree(50)
-		var inserted []int
+		// Дерево хранит каждое значение один раз, начиная с корня 50.
+		inserted := []int{50}
 
 		for i := 0; i < 1000; i++ {
-			value := rand.Intn(100)
-			op := rand.Intn(2)
+			value := rnd.Intn(100)
+			op := rnd.Intn(2)
 
 			if op == 0 {
 				tree.Insert(value)
-				inserted = append(inserted, value)
+				if !containsInt(inserted, value) {
+					inserted = append(inserted, value)
+				}
 			} else if len(inserted) > 0 {
-				index := rand.Intn(len(inserted))
+				index := rnd.Intn(len(inserted))
 				valueToDelete := inserted[index]
 				tree.Delete(valueToDelete)
 				inserted = append(inserted[:index], inserted[index+1:]...)
@@ -204,8 +203,8 @@ func TestRandomInsertDelete(t *testing.T) {
 
 		// Проверяем, что элементы в дереве соответствуют вставленным.
 		inOrder := InOrderTraversal(tree.Root)
-		expected := make([]int, len(inserted))
-		copy(expected, inserted)
+		// Пустое дерево обходится в nil, поэтому и ожидаемый срез копируется в nil.
+		expected := append([]int(nil), inserted...)
 		sortIntSlice(expected)
 
 		if !reflect.DeepEqual(inOrder, expected) {
@@ -214,6 +213,16 @@ func TestRandomInsertDelete(t *testing.T) {
 	})
 }
 
+// containsInt сообщает, есть ли значение v в срезе.
+func containsInt(slice []int, v int) bool {
+	for _, x := range slice {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
 func sortIntSlice(slice []int) {
 	for i := 0; i < len(slice)-1; i++ {
 		for j := i + 1; j < len(slice); j++ {
@@ -0,0 +1,206 @@
+package main
+
+const (
+	ultimateSize  = boardSize * boardSize
+	ultimateDepth = 5
+)
+
+// UltimateMove задаёт ход в «ультимативных» крестиках-ноликах: номер малого поля и клетку в нём.
+type UltimateMove struct {
+	Board, Row, Col int
+}
+
+// UltimateBoard хранит состояние вложенной игры 9×9: малые поля, мета-поле и поле для следующего хода.
+type UltimateBoard struct {
+	Small  [ultimateSize][boardSize][boardSize]Player
+	Meta   [boardSize][boardSize]Player
+	Drawn  [ultimateSize]bool
+	Active int // Индекс малого поля для следующего хода, -1 — любое открытое поле.
+	Turn   Player
+}
+
+// newUltimateBoard создаёт пустую партию, в которой первыми ходят крестики.
+func newUltimateBoard() UltimateBoard {
+	return UltimateBoard{Active: -1, Turn: Cross}
+}
+
+// closed сообщает, что малое поле уже выиграно или заполнено вничью.
+func (u *UltimateBoard) closed(b int) bool {
+	return u.Meta[b/boardSize][b%boardSize] != Empty || u.Drawn[b]
+}
+
+// legalMoves возвращает все допустимые ходы.
+func (u *UltimateBoard) legalMoves() []UltimateMove {
+	var moves []UltimateMove
+	if u.Winner() != Empty {
+		return moves
+	}
+	for b := 0; b < ultimateSize; b++ {
+		if u.closed(b) || (u.Active != -1 && u.Active != b) {
+			continue
+		}
+		for i := 0; i < boardSize; i++ {
+			for j := 0; j < boardSize; j++ {
+				if u.Small[b][i][j] == Empty {
+					moves = append(moves, UltimateMove{b, i, j})
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// play возвращает позицию после хода m. Исходная позиция не меняется.
+func (u UltimateBoard) play(m UltimateMove) UltimateBoard {
+	u.Small[m.Board][m.Row][m.Col] = u.Turn
+
+	if checkWin(u.Small[m.Board], u.Turn) {
+		u.Meta[m.Board/boardSize][m.Board%boardSize] = u.Turn
+	} else if evaluate(u.Small[m.Board]) == 0 {
+		u.Drawn[m.Board] = true
+	}
+
+	// Соперник отправляется на малое поле, соответствующее клетке хода.
+	u.Active = m.Row*boardSize + m.Col
+	if u.closed(u.Active) {
+		u.Active = -1
+	}
+
+	u.Turn = other(u.Turn)
+	return u
+}
+
+// Winner возвращает игрока, собравшего линию на мета-поле.
+func (u *UltimateBoard) Winner() Player {
+	if checkWin(u.Meta, Circle) {
+		return Circle
+	}
+	if checkWin(u.Meta, Cross) {
+		return Cross
+	}
+	return Empty
+}
+
+// lineThreats считает линии, где у игрока p две фишки, а третья клетка свободна.
+func lineThreats(b [boardSize][boardSize]Player, p Player) int {
+	threats := 0
+	count := func(cells [boardSize]Player) {
+		own, empty := 0, 0
+		for _, c := range cells {
+			switch c {
+			case p:
+				own++
+			case Empty:
+				empty++
+			}
+		}
+		if own == boardSize-1 && empty == 1 {
+			threats++
+		}
+	}
+	for i := 0; i < boardSize; i++ {
+		count([boardSize]Player{b[i][0], b[i][1], b[i][2]})
+		count([boardSize]Player{b[0][i], b[1][i], b[2][i]})
+	}
+	count([boardSize]Player{b[0][0], b[1][1], b[2][2]})
+	count([boardSize]Player{b[0][2], b[1][1], b[2][0]})
+	return threats
+}
+
+// ultimateHeuristic оценивает позицию с точки зрения ноликов:
+// взятые малые поля, угрозы на мета-поле и угрозы внутри открытых малых полей.
+func ultimateHeuristic(u *UltimateBoard) int {
+	score := 0
+	for b := 0; b < ultimateSize; b++ {
+		weight := 10
+		if b == ultimateSize/2 {
+			weight = 15
+		}
+		switch u.Meta[b/boardSize][b%boardSize] {
+		case Circle:
+			score += weight
+		case Cross:
+			score -= weight
+		default:
+			if !u.Drawn[b] {
+				score += 2 * (lineThreats(u.Small[b], Circle) - lineThreats(u.Small[b], Cross))
+			}
+		}
+	}
+	score += 30 * (lineThreats(u.Meta, Circle) - lineThreats(u.Meta, Cross))
+	return score
+}
+
+func (u *UltimateBoard) Name() string {
+	return modeUltimate
+}
+
+func (u *UltimateBoard) ToMove() Player {
+	return u.Turn
+}
+
+// ultimateCell переводит клетку поля 9×9 в номер малого поля и клетку внутри него.
+func ultimateCell(row, col int) UltimateMove {
+	return UltimateMove{Board: (row/boardSize)*boardSize + col/boardSize, Row: row % boardSize, Col: col % boardSize}
+}
+
+func (u *UltimateBoard) At(c Cell3) Player {
+	m := ultimateCell(c.Row, c.Col)
+	return u.Small[m.Board][m.Row][m.Col]
+}
+
+func (u *UltimateBoard) LegalMoves() []Move {
+	var moves []Move
+	for _, m := range u.legalMoves() {
+		moves = append(moves, Move{
+			Row:    (m.Board/boardSize)*boardSize + m.Row,
+			Col:    (m.Board%boardSize)*boardSize + m.Col,
+			Symbol: u.Turn,
+		})
+	}
+	return moves
+}
+
+func (u *UltimateBoard) Apply(m Move) Rules {
+	next := u.play(ultimateCell(m.Row, m.Col))
+	return &next
+}
+
+func (u *UltimateBoard) Terminal() bool {
+	return u.Winner() != Empty || len(u.legalMoves()) == 0
+}
+
+func (u *UltimateBoard) Evaluate() int {
+	if u.Turn == Cross {
+		return -ultimateHeuristic(u)
+	}
+	return ultimateHeuristic(u)
+}
+
+// Hints подсвечивает малые поля, куда можно ходить, закрашивает взятые поля
+// и проводит выигрышную линию через центры малых полей.
+func (u *UltimateBoard) Hints() RenderHints {
+	h := RenderHints{Layers: 1, Rows: ultimateSize, Cols: ultimateSize, Block: boardSize, Marked: make(map[Cell3]Player)}
+	open := !u.Terminal()
+	for b := 0; b < ultimateSize; b++ {
+		owner := u.Meta[b/boardSize][b%boardSize]
+		active := open && !u.closed(b) && (u.Active == -1 || u.Active == b)
+		for i := 0; i < boardSize; i++ {
+			for j := 0; j < boardSize; j++ {
+				c := Cell3{0, (b/boardSize)*boardSize + i, (b%boardSize)*boardSize + j}
+				if owner != Empty {
+					h.Marked[c] = owner
+				}
+				if active {
+					h.Active = append(h.Active, c)
+				}
+			}
+		}
+	}
+
+	_, line := findLine(func(row, col int) Player { return u.Meta[row][col] }, boardSize, boardSize, boardSize)
+	for _, c := range line {
+		h.Line = append(h.Line, Cell3{0, c.Row*boardSize + 1, c.Col*boardSize + 1})
+	}
+	return h
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestUltimatePlay(t *testing.T) {
+	u := newUltimateBoard()
+
+	// Первый ход можно сделать в любое из 81 поля.
+	if moves := u.legalMoves(); len(moves) != 81 {
+		t.Fatalf("Ожидалось 81 ход, но получено %d", len(moves))
+	}
+
+	// Ход в клетку (0, 2) отправляет соперника на малое поле 2.
+	u = u.play(UltimateMove{Board: 4, Row: 0, Col: 2})
+	if u.Active != 2 || u.Turn != Circle {
+		t.Errorf("Ожидалось активное поле 2 и ход ноликов, но получено %d и %v", u.Active, u.Turn)
+	}
+	for _, m := range u.legalMoves() {
+		if m.Board != 2 {
+			t.Fatalf("Ход %v вне активного поля", m)
+		}
+	}
+}
+
+func TestUltimateClaimAndWin(t *testing.T) {
+	u := newUltimateBoard()
+	u.Small[0] = [3][3]Player{
+		{Cross, Cross, Empty},
+		{Empty, Empty, Empty},
+		{Empty, Empty, Empty},
+	}
+	u.Active = 0
+
+	// Выигрыш малого поля занимает клетку мета-поля.
+	u = u.play(UltimateMove{Board: 0, Row: 0, Col: 2})
+	if u.Meta[0][0] != Cross {
+		t.Errorf("Ожидалось, что крестики заберут поле 0")
+	}
+	if u.Active != 2 {
+		t.Errorf("Ожидалось активное поле 2, но получено %d", u.Active)
+	}
+
+	u.Meta[0][1] = Cross
+	u.Small[2] = [3][3]Player{
+		{Empty, Empty, Empty},
+		{Cross, Cross, Empty},
+		{Empty, Empty, Empty},
+	}
+	u.Active = 2
+	u.Turn = Cross
+	u = u.play(UltimateMove{Board: 2, Row: 1, Col: 2})
+	if u.Winner() != Cross {
+		t.Errorf("Ожидалась победа крестиков на мета-поле")
+	}
+	if moves := u.legalMoves(); len(moves) != 0 {
+		t.Errorf("После победы не должно быть ходов, но получено %d", len(moves))
+	}
+
+	// Ход, отправляющий на закрытое поле, разрешает сопернику любое открытое поле.
+	u = newUltimateBoard()
+	u.Meta[1][1] = Circle
+	u = u.play(UltimateMove{Board: 0, Row: 1, Col: 1})
+	if u.Active != -1 {
+		t.Errorf("Поле 4 закрыто, ожидалось свободное поле, но получено %d", u.Active)
+	}
+}
+
+func TestUltimateBestMove(t *testing.T) {
+	// Нолики выигрывают партию, забрав поле 2 ходом в клетку (2, 2).
+	u := newUltimateBoard()
+	u.Meta[0][0] = Circle
+	u.Meta[0][1] = Circle
+	u.Small[2] = [3][3]Player{
+		{Circle, Empty, Empty},
+		{Empty, Circle, Empty},
+		{Empty, Empty, Empty},
+	}
+	u.Small[4][0][0] = Cross
+	u.Active = 2
+	u.Turn = Circle
+
+	// Клетка (2, 2) поля 2 в общих координатах 9×9 — строка 2, столбец 8.
+	m, _, ok := bestMove(&u, ultimateDepth)
+	expected := Move{Row: 2, Col: 8, Symbol: Circle}
+	if !ok || m != expected {
+		t.Errorf("Ожидался ход %v, но получен %v", expected, m)
+	}
+
+	// Крестики обязаны помешать той же угрозе.
+	u.Turn = Cross
+	expected.Symbol = Cross
+	m, _, ok = bestMove(&u, ultimateDepth)
+	if !ok || m != expected {
+		t.Errorf("Ожидался ход %v, но получен %v", expected, m)
+	}
+}
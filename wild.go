@@ -0,0 +1,162 @@
+package main
+
+import (
+	"math"
+	"sync"
+)
+
+// WildMove задаёт ход в «диких» крестиках-ноликах: клетку и выбранный игроком символ.
+type WildMove struct {
+	Row, Col int
+	Symbol   Player
+}
+
+// WildBoard хранит позицию «диких» крестиков-ноликов. Turn — ходящий игрок:
+// первым ходит Cross, вторым — Circle; ставить каждый из них может любой символ.
+type WildBoard struct {
+	Cells [boardSize][boardSize]Player
+	Turn  Player
+}
+
+// newWildBoard создаёт пустую партию.
+func newWildBoard() WildBoard {
+	return WildBoard{Turn: Cross}
+}
+
+// completed сообщает, собрана ли на поле линия из любых одинаковых символов.
+func (w *WildBoard) completed() bool {
+	return checkWin(w.Cells, Circle) || checkWin(w.Cells, Cross)
+}
+
+// legalMoves возвращает ходы в каждую пустую клетку каждым из двух символов.
+func (w *WildBoard) legalMoves() []WildMove {
+	var moves []WildMove
+	if w.completed() {
+		return moves
+	}
+	for i := 0; i < boardSize; i++ {
+		for j := 0; j < boardSize; j++ {
+			if w.Cells[i][j] == Empty {
+				moves = append(moves, WildMove{i, j, Cross}, WildMove{i, j, Circle})
+			}
+		}
+	}
+	return moves
+}
+
+// play возвращает позицию после хода m.
+func (w WildBoard) play(m WildMove) WildBoard {
+	w.Cells[m.Row][m.Col] = m.Symbol
+	w.Turn = other(w.Turn)
+	return w
+}
+
+// wildEvaluate оценивает позицию для игрока, только что сделавшего ход:
+// 1 — он собрал линию (любого символа) и выиграл, 0 — ничья, -2 — партия не окончена.
+func wildEvaluate(cells [boardSize][boardSize]Player) int {
+	if checkWin(cells, Circle) || checkWin(cells, Cross) {
+		return 1
+	}
+	for i := 0; i < boardSize; i++ {
+		for j := 0; j < boardSize; j++ {
+			if cells[i][j] == Empty {
+				return -2
+			}
+		}
+	}
+	return 0
+}
+
+// wildSearch — негамакс для стороны, которая ходит: 1 — выигрыш, -1 — проигрыш, 0 — ничья.
+// Состояние не зависит от того, кто поставил какой символ, поэтому
+// результаты кэшируются по одному расположению символов.
+func wildSearch(cells [boardSize][boardSize]Player, memo map[[boardSize][boardSize]Player]int) int {
+	if v, ok := memo[cells]; ok {
+		return v
+	}
+
+	best := math.MinInt32
+	w := WildBoard{Cells: cells}
+	for _, m := range w.legalMoves() {
+		next := w.play(m)
+		var val int
+		switch wildEvaluate(next.Cells) {
+		case 1:
+			val = 1
+		case 0:
+			val = 0
+		default:
+			val = -wildSearch(next.Cells, memo)
+		}
+		best = max(best, val)
+		if best == 1 {
+			break
+		}
+	}
+	memo[cells] = best
+	return best
+}
+
+// wildMemo хранит оценки позиций между ходами компьютера.
+var (
+	wildMemo   = make(map[[boardSize][boardSize]Player]int)
+	wildMemoMu sync.Mutex
+)
+
+// wildValue возвращает точную оценку позиции для ходящего, используя общий кэш.
+func wildValue(cells [boardSize][boardSize]Player) int {
+	wildMemoMu.Lock()
+	defer wildMemoMu.Unlock()
+	return wildSearch(cells, wildMemo)
+}
+
+func (w *WildBoard) Name() string {
+	return modeWild
+}
+
+func (w *WildBoard) ToMove() Player {
+	return w.Turn
+}
+
+func (w *WildBoard) At(c Cell3) Player {
+	return w.Cells[c.Row][c.Col]
+}
+
+func (w *WildBoard) LegalMoves() []Move {
+	var moves []Move
+	for _, m := range w.legalMoves() {
+		moves = append(moves, Move{Row: m.Row, Col: m.Col, Symbol: m.Symbol})
+	}
+	return moves
+}
+
+func (w *WildBoard) Apply(m Move) Rules {
+	next := w.play(WildMove{m.Row, m.Col, m.Symbol})
+	return &next
+}
+
+func (w *WildBoard) Terminal() bool {
+	return wildEvaluate(w.Cells) != -2
+}
+
+// Winner возвращает игрока, собравшего линию: это всегда тот, кто сделал последний ход.
+func (w *WildBoard) Winner() Player {
+	if w.completed() {
+		return other(w.Turn)
+	}
+	return Empty
+}
+
+// Evaluate берёт точную оценку из перебора с кэшем, поэтому достаточно перебора на один полуход.
+func (w *WildBoard) Evaluate() int {
+	return wildValue(w.Cells) * winScore / 2
+}
+
+func (w *WildBoard) Hints() RenderHints {
+	h := RenderHints{Layers: 1, Rows: boardSize, Cols: boardSize, Symbols: true}
+	_, line := findLine(func(row, col int) Player { return w.Cells[row][col] }, boardSize, boardSize, boardSize)
+	for _, c := range line {
+		h.Line = append(h.Line, Cell3{0, c.Row, c.Col})
+	}
+	return h
+}
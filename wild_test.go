@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestWildEvaluate(t *testing.T) {
+	// Линию из ноликов может собрать любой игрок, и она засчитывается тому, кто сходил.
+	cells := [3][3]Player{
+		{Circle, Circle, Circle},
+		{Cross, Empty, Empty},
+		{Empty, Empty, Cross},
+	}
+	if result := wildEvaluate(cells); result != 1 {
+		t.Errorf("Ожидалось 1, но получено %d", result)
+	}
+
+	cells[0][2] = Empty
+	if result := wildEvaluate(cells); result != -2 {
+		t.Errorf("Ожидалось -2, но получено %d", result)
+	}
+}
+
+func TestWildBestMove(t *testing.T) {
+	// Компьютер выигрывает, поставив X в (0, 2): символ выбирается так же, как клетка.
+	w := WildBoard{
+		Cells: [3][3]Player{
+			{Cross, Cross, Empty},
+			{Circle, Empty, Empty},
+			{Empty, Empty, Empty},
+		},
+		Turn: Circle,
+	}
+	m, _, ok := bestMove(&w, 1)
+	if !ok || w.Apply(m).Winner() != Circle {
+		t.Errorf("Ход %v не выигрывает сразу", m)
+	}
+
+	// Первый игрок в «диких» крестиках-ноликах выигрывает при правильной игре.
+	empty := newWildBoard()
+	if v := wildValue(empty.Cells); v != 1 {
+		t.Errorf("Ожидался выигрыш первого игрока, но получено %d", v)
+	}
+
+	// В пустой позиции лучший ход — центр, после которого у соперника нет спасения.
+	m, _, ok = bestMove(&empty, 1)
+	next := empty.play(WildMove{m.Row, m.Col, m.Symbol})
+	if !ok || wildValue(next.Cells) != -1 {
+		t.Errorf("Ход %v не сохраняет выигрыш", m)
+	}
+}